@@ -0,0 +1,226 @@
+package database
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AddrStrategy defines how a node of Config.Addrs is selected
+// for a new connection pool.
+type AddrStrategy int
+
+const (
+	// RandomAddrs selects a random available node.
+	RandomAddrs AddrStrategy = iota
+	// RoundRobinAddrs selects available nodes in turn.
+	RoundRobinAddrs
+	// PreferredAddrs selects the first available node in order of
+	// Config.Addrs, e.g. nodes of the local zone should go first.
+	PreferredAddrs
+)
+
+const DefaultAddrDownTimeout = time.Second * 30
+
+// AddrPolicy defines selection of a node of Config.Addrs. All connections
+// of a pool use the same node, other node is selected on reconnect.
+//
+// Node is excluded from selection for DownTimeout when connect to it fails
+// or its connections are broken. If all nodes are excluded, they are
+// selected by Strategy anyway.
+type AddrPolicy struct {
+	// Strategy selects a node among available ones.
+	Strategy AddrStrategy
+
+	// DownTimeout is the time for which failed node is excluded from selection.
+	// Zero value disables exclusion.
+	//
+	// This field must not be negative.
+	DownTimeout time.Duration
+
+	// RebalanceInterval is the interval of checks whether connections should
+	// move to other node. They are moved when the node in use is excluded,
+	// a more preferred node is available for PreferredAddrs or all nodes
+	// excluded at selection have recovered for other strategies.
+	// Zero value disables rebalancing.
+	//
+	// This field must not be negative.
+	RebalanceInterval time.Duration
+}
+
+func (ap *AddrPolicy) validate() error {
+	switch ap.Strategy {
+	case RandomAddrs, RoundRobinAddrs, PreferredAddrs:
+	default:
+		return fmt.Errorf("%w: AddrPolicy: unknown Strategy %d", ErrInvalidConfig, ap.Strategy)
+	}
+
+	if ap.DownTimeout < 0 {
+		return fmt.Errorf("%w: AddrPolicy: DownTimeout must not be negative", ErrInvalidConfig)
+	}
+
+	if ap.RebalanceInterval < 0 {
+		return fmt.Errorf("%w: AddrPolicy: RebalanceInterval must not be negative", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// addrSet selects nodes of Config.Addrs and tracks failed ones.
+type addrSet struct {
+	addrs  []string
+	policy AddrPolicy
+
+	next atomic.Uint64
+
+	mu        sync.Mutex
+	downUntil map[string]time.Time
+	current   string
+	// degraded is set if some nodes were excluded when current was selected.
+	degraded bool
+}
+
+func newAddrSet(cfg *Config, policy AddrPolicy) *addrSet {
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{cfg.Addr}
+	}
+
+	return &addrSet{
+		addrs:     addrs,
+		policy:    policy,
+		downUntil: make(map[string]time.Time),
+	}
+}
+
+// candidates returns nodes in order of selection by strategy.
+// Available nodes go before excluded ones.
+func (s *addrSet) candidates() []string {
+	ordered := make([]string, len(s.addrs))
+
+	switch s.policy.Strategy {
+	case RoundRobinAddrs:
+		start := int(s.next.Add(1) % uint64(len(s.addrs)))
+
+		for i := range s.addrs {
+			ordered[i] = s.addrs[(start+i)%len(s.addrs)]
+		}
+	case RandomAddrs:
+		for i, j := range rand.Perm(len(s.addrs)) { //nolint:gosec // not a security issue.
+			ordered[i] = s.addrs[j]
+		}
+	case PreferredAddrs:
+		copy(ordered, s.addrs)
+	}
+
+	var (
+		now       = time.Now()
+		available = make([]string, 0, len(ordered))
+		excluded  []string
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, addr := range ordered {
+		if s.down(addr, now) {
+			excluded = append(excluded, addr)
+		} else {
+			available = append(available, addr)
+		}
+	}
+
+	return append(available, excluded...)
+}
+
+// use records node of the new pool.
+func (s *addrSet) use(addr string) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current = addr
+	s.degraded = false
+
+	for _, other := range s.addrs {
+		if s.down(other, now) {
+			s.degraded = true
+		}
+	}
+}
+
+// fail excludes node from selection for DownTimeout.
+func (s *addrSet) fail(addr string) {
+	if s.policy.DownTimeout <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.downUntil[addr] = time.Now().Add(s.policy.DownTimeout)
+}
+
+// failCurrent excludes node of the current pool.
+func (s *addrSet) failCurrent() {
+	s.mu.Lock()
+	current := s.current
+	s.mu.Unlock()
+
+	s.fail(current)
+}
+
+// shouldRebalance reports whether connections should move to other node.
+func (s *addrSet) shouldRebalance() bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstAvailable string
+
+	recovered := true
+
+	for _, addr := range s.addrs {
+		if s.down(addr, now) {
+			recovered = false
+
+			continue
+		}
+
+		if firstAvailable == "" {
+			firstAvailable = addr
+		}
+	}
+
+	switch {
+	case firstAvailable == "":
+		return false
+	case s.down(s.current, now):
+		return true
+	case s.policy.Strategy == PreferredAddrs:
+		return firstAvailable != s.current
+	default:
+		return s.degraded && recovered
+	}
+}
+
+func (s *addrSet) down(addr string, now time.Time) bool {
+	return s.downUntil[addr].After(now)
+}
+
+// startRebalance starts rebalancing of connections between Config.Addrs.
+func (db *DB) startRebalance() {
+	if db.options.addrPolicy.RebalanceInterval <= 0 || len(db.baseCfg.Addrs) < 2 {
+		return
+	}
+
+	db.rebalancer = startPeriodic(db.options.addrPolicy.RebalanceInterval, func() {
+		if db.addrs.shouldRebalance() {
+			_ = db.reconnector.reconnect(nil)
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loghole/database/hooks"
+)
+
+func TestAddrSet_candidates(t *testing.T) {
+	addrs := []string{"node-1", "node-2", "node-3"}
+
+	tests := []struct {
+		name   string
+		policy AddrPolicy
+		failed []string
+		want   [][]string
+	}{
+		{
+			name:   "round robin",
+			policy: AddrPolicy{Strategy: RoundRobinAddrs, DownTimeout: time.Minute},
+			want: [][]string{
+				{"node-2", "node-3", "node-1"},
+				{"node-3", "node-1", "node-2"},
+				{"node-1", "node-2", "node-3"},
+			},
+		},
+		{
+			name:   "round robin skip failed",
+			policy: AddrPolicy{Strategy: RoundRobinAddrs, DownTimeout: time.Minute},
+			failed: []string{"node-3"},
+			want: [][]string{
+				{"node-2", "node-1", "node-3"},
+				{"node-1", "node-2", "node-3"},
+			},
+		},
+		{
+			name:   "preferred",
+			policy: AddrPolicy{Strategy: PreferredAddrs, DownTimeout: time.Minute},
+			want: [][]string{
+				{"node-1", "node-2", "node-3"},
+				{"node-1", "node-2", "node-3"},
+			},
+		},
+		{
+			name:   "preferred skip failed",
+			policy: AddrPolicy{Strategy: PreferredAddrs, DownTimeout: time.Minute},
+			failed: []string{"node-1", "node-2"},
+			want: [][]string{
+				{"node-3", "node-1", "node-2"},
+			},
+		},
+		{
+			name:   "exclusion disabled",
+			policy: AddrPolicy{Strategy: PreferredAddrs},
+			failed: []string{"node-1"},
+			want: [][]string{
+				{"node-1", "node-2", "node-3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newAddrSet(&Config{Addrs: addrs}, tt.policy)
+
+			for _, addr := range tt.failed {
+				set.fail(addr)
+			}
+
+			for i, want := range tt.want {
+				assert.Equal(t, want, set.candidates(), "candidates #%d", i)
+			}
+		})
+	}
+}
+
+func TestAddrSet_candidatesRandom(t *testing.T) {
+	set := newAddrSet(&Config{Addrs: []string{"node-1", "node-2", "node-3"}}, AddrPolicy{
+		Strategy:    RandomAddrs,
+		DownTimeout: time.Minute,
+	})
+
+	set.fail("node-2")
+
+	for i := 0; i < 10; i++ {
+		got := set.candidates()
+
+		assert.ElementsMatch(t, []string{"node-1", "node-2", "node-3"}, got)
+		assert.Equal(t, "node-2", got[2], "failed node must be the last")
+	}
+}
+
+func TestAddrSet_shouldRebalance(t *testing.T) {
+	addrs := []string{"node-1", "node-2"}
+
+	tests := []struct {
+		name     string
+		strategy AddrStrategy
+		prepare  func(set *addrSet)
+		want     bool
+	}{
+		{
+			name:     "balanced",
+			strategy: RoundRobinAddrs,
+			prepare:  func(set *addrSet) { set.use("node-2") },
+			want:     false,
+		},
+		{
+			name:     "current failed",
+			strategy: RoundRobinAddrs,
+			prepare: func(set *addrSet) {
+				set.use("node-2")
+				set.fail("node-2")
+			},
+			want: true,
+		},
+		{
+			name:     "all failed",
+			strategy: RoundRobinAddrs,
+			prepare: func(set *addrSet) {
+				set.use("node-2")
+				set.fail("node-1")
+				set.fail("node-2")
+			},
+			want: false,
+		},
+		{
+			name:     "node recovered",
+			strategy: RoundRobinAddrs,
+			prepare: func(set *addrSet) {
+				set.fail("node-1")
+				set.use("node-2")
+				set.downUntil["node-1"] = time.Now().Add(-time.Second)
+			},
+			want: true,
+		},
+		{
+			name:     "node still failed",
+			strategy: RoundRobinAddrs,
+			prepare: func(set *addrSet) {
+				set.fail("node-1")
+				set.use("node-2")
+			},
+			want: false,
+		},
+		{
+			name:     "preferred available",
+			strategy: PreferredAddrs,
+			prepare:  func(set *addrSet) { set.use("node-2") },
+			want:     true,
+		},
+		{
+			name:     "preferred in use",
+			strategy: PreferredAddrs,
+			prepare:  func(set *addrSet) { set.use("node-1") },
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newAddrSet(&Config{Addrs: addrs}, AddrPolicy{Strategy: tt.strategy, DownTimeout: time.Minute})
+
+			tt.prepare(set)
+
+			assert.Equal(t, tt.want, set.shouldRebalance())
+		})
+	}
+}
+
+func TestNew_addrsFailover(t *testing.T) {
+	cfg := &Config{
+		Addrs:    []string{"127.0.0.1:1", "127.0.0.1:2"},
+		User:     "postgres",
+		Database: "database",
+		Type:     PGXDatabase,
+	}
+
+	_, err := New(cfg, WithAddrPolicy(AddrPolicy{Strategy: PreferredAddrs, DownTimeout: time.Minute}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "127.0.0.1:1")
+	assert.Contains(t, err.Error(), "127.0.0.1:2")
+
+	db, err := New(cfg, WithLazyConnect(), WithAddrPolicy(AddrPolicy{Strategy: PreferredAddrs}))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	assert.Equal(t, "127.0.0.1:1", db.hooksCfg.CurrentAddr())
+}
+
+func TestWithAddrPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  AddrPolicy
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "pass",
+			policy:  AddrPolicy{Strategy: PreferredAddrs, DownTimeout: DefaultAddrDownTimeout, RebalanceInterval: time.Minute},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown Strategy",
+			policy:  AddrPolicy{Strategy: -1},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid DownTimeout",
+			policy:  AddrPolicy{DownTimeout: -1},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid RebalanceInterval",
+			policy:  AddrPolicy{RebalanceInterval: -1},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts options
+
+			err := opts.apply(&hooks.Config{}, WithAddrPolicy(tt.policy))
+
+			tt.wantErr(t, err, "validate()")
+		})
+	}
+}
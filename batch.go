@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/loghole/dbhook"
+
+	"github.com/loghole/database/hooks"
+	"github.com/loghole/database/internal/dbsqlx"
+)
+
+// SendBatch sends all queued queries of the batch to the server at once and
+// reads their results. It is retried by retry policy as a whole, so queries
+// of the batch must be idempotent or run in a single transaction.
+//
+// SendBatch is supported by PGXDatabase only, results of queued queries are
+// passed to their QueuedQuery callbacks.
+func (db *DB) SendBatch(ctx context.Context, batch *pgx.Batch) error {
+	db.markWrite()
+
+	input := &dbhook.HookInput{Query: batchQuery(batch), Caller: hooks.CallerBatch}
+
+	return db.do(ctx, "SendBatch", func(ctx context.Context) error {
+		return db.pgxConn(ctx, func(conn *pgx.Conn) error {
+			return runHook(ctx, db.hook, input, func(ctx context.Context) error {
+				return conn.SendBatch(ctx, batch).Close() //nolint:wrapcheck // need clean err.
+			})
+		})
+	})
+}
+
+// CopyFrom copies rows of src to the table with COPY protocol and returns
+// the number of copied rows. CopyFrom is not retried because src can't be
+// read twice.
+//
+// CopyFrom is supported by PGXDatabase only.
+func (db *DB) CopyFrom(
+	ctx context.Context,
+	table pgx.Identifier,
+	columns []string,
+	src pgx.CopyFromSource,
+) (count int64, err error) {
+	db.markWrite()
+
+	ctx, done, err := db.tracker.begin(ctx, "CopyFrom", true)
+	if err != nil {
+		return 0, err
+	}
+
+	defer done()
+
+	input := &dbhook.HookInput{Query: copyQuery(table, columns), Caller: hooks.CallerCopy}
+
+	err = db.pgxConn(ctx, func(conn *pgx.Conn) error {
+		return runHook(ctx, db.hook, input, func(ctx context.Context) error {
+			count, err = conn.CopyFrom(ctx, table, columns, src)
+
+			return err //nolint:wrapcheck // need clean err.
+		})
+	})
+
+	return count, err
+}
+
+// pgxConn runs fn with pgx connection of the current pool.
+func (db *DB) pgxConn(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	if db.baseCfg.Type != PGXDatabase {
+		return fmt.Errorf("%w: %s database", ErrNotSupported, db.baseCfg.Type)
+	}
+
+	conn, err := db.SQLx().Conn(ctx)
+	if err != nil {
+		return err //nolint:wrapcheck // need clean err.
+	}
+
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		pgxConn, ok := dbsqlx.UnwrapConn(driverConn).(interface{ Conn() *pgx.Conn })
+		if !ok {
+			return fmt.Errorf("%w: %T connection", ErrNotSupported, driverConn)
+		}
+
+		return fn(pgxConn.Conn())
+	})
+}
+
+// batchQuery returns queued queries of the batch for hooks.
+func batchQuery(batch *pgx.Batch) string {
+	queries := make([]string, 0, batch.Len())
+
+	for _, queued := range batch.QueuedQueries {
+		queries = append(queries, queued.SQL)
+	}
+
+	return strings.Join(queries, ";\n")
+}
+
+// copyQuery returns COPY statement equivalent to CopyFrom for hooks.
+func copyQuery(table pgx.Identifier, columns []string) string {
+	quoted := make([]string, len(columns))
+
+	for i, column := range columns {
+		quoted[i] = pgx.Identifier{column}.Sanitize()
+	}
+
+	return fmt.Sprintf("COPY %s (%s) FROM STDIN", table.Sanitize(), strings.Join(quoted, ", "))
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_SendBatch_notSupported(t *testing.T) {
+	db, err := New(&Config{Database: ":memory:", Type: SQLiteDatabase})
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	batch := &pgx.Batch{}
+	batch.Queue("SELECT 1")
+
+	assert.ErrorIs(t, db.SendBatch(context.Background(), batch), ErrNotSupported)
+
+	count, err := db.CopyFrom(context.Background(), pgx.Identifier{"users"}, []string{"id"}, pgx.CopyFromRows(nil))
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.Zero(t, count)
+}
+
+func Test_batchQuery(t *testing.T) {
+	batch := &pgx.Batch{}
+	batch.Queue("INSERT INTO users (id) VALUES ($1)", 1)
+	batch.Queue("UPDATE counters SET value = value + 1")
+
+	assert.Equal(t, "INSERT INTO users (id) VALUES ($1);\nUPDATE counters SET value = value + 1", batchQuery(batch))
+}
+
+func Test_copyQuery(t *testing.T) {
+	assert.Equal(t,
+		`COPY "public"."users" ("id", "name") FROM STDIN`,
+		copyQuery(pgx.Identifier{"public", "users"}, []string{"id", "name"}),
+	)
+}
@@ -3,8 +3,10 @@ package database
 import (
 	"fmt"
 	"math/rand"
+	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/loghole/database/hooks"
 )
@@ -17,6 +19,7 @@ const (
 	CockroachDatabase  DBType = "cockroach"
 	ClickhouseDatabase DBType = "clickhouse"
 	SQLiteDatabase     DBType = "sqlite3"
+	MySQLDatabase      DBType = "mysql" // also used for MariaDB.
 )
 
 func (d DBType) String() string {
@@ -28,83 +31,186 @@ func (d DBType) String() string {
 }
 
 type Config struct {
-	Addr         string
-	Addrs        []string // for cockroachdb
-	User         string
-	Database     string
-	Type         DBType
-	ReadTimeout  string
-	WriteTimeout string
-	Params       map[string]string
-
-	// Deprecated: use Params for sets certs.
-	CertPath string
+	Addr         string            `yaml:"addr" json:"addr"`
+	Addrs        []string          `yaml:"addrs" json:"addrs"`                 // nodes of cluster, see WithAddrPolicy
+	ReplicaAddrs []string          `yaml:"replica_addrs" json:"replica_addrs"` // read replicas, see WithReplicaStrategy
+	User         string            `yaml:"user" json:"user"`                   // user or user:password, not percent-encoded
+	Database     string            `yaml:"database" json:"database"`
+	Type         DBType            `yaml:"type" json:"type"`
+	Params       map[string]string `yaml:"params" json:"params"`
+
+	// Timeouts defines connect and query timeouts.
+	Timeouts Timeouts `yaml:"timeouts" json:"timeouts"`
+
+	// Deprecated: use Timeouts. ReadTimeout and WriteTimeout are
+	// supported by clickhouse only.
+	ReadTimeout  string `yaml:"read_timeout" json:"read_timeout"`
+	WriteTimeout string `yaml:"write_timeout" json:"write_timeout"`
+
+	// Connection pool settings. They are applied to the pool created by New
+	// and re-applied to every pool created on reconnect.
+	// Zero values keep the database/sql defaults.
+	MaxOpenConns    int           `yaml:"max_open_conns" json:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" json:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" json:"conn_max_idle_time"`
+
+	// Credentials provides user and password for every new connection.
+	// If it is set, password from User is ignored and User is used
+	// when provider returns empty user name.
+	Credentials CredentialsProvider `yaml:"-" json:"-"`
+
+	// TLS defines TLS settings of connections.
+	TLS *TLSConfig `yaml:"tls" json:"tls"`
+
+	// Deprecated: use TLS. CertPath is a directory with ca.crt,
+	// client.postgres.crt and client.postgres.key files.
+	CertPath string `yaml:"cert_path" json:"cert_path"`
+
+	// driverSSLMode leaves postgres sslmode to the driver instead of
+	// disabling TLS, it is set by ParseDSN for DSN without sslmode.
+	driverSSLMode bool
 }
 
+// DSN returns data source name. For several Addrs a random node is used,
+// DB selects nodes with AddrPolicy instead.
 func (cfg *Config) DSN() string {
+	return cfg.dsn(cfg.addr(), cfg.credentials())
+}
+
+func (cfg *Config) dsn(addr string, creds Credentials) string {
 	switch cfg.Type {
 	case PostgresDatabase, PGXDatabase, CockroachDatabase:
-		return cfg.postgresConnString()
+		return cfg.postgresConnString(addr, creds)
 	case ClickhouseDatabase:
-		return cfg.clickhouseConnString()
+		return cfg.clickhouseConnString(addr, creds)
 	case SQLiteDatabase:
 		return cfg.sqliteConnString()
+	case MySQLDatabase:
+		return cfg.mysqlConnString(addr, creds)
 	default:
 		return ""
 	}
 }
 
-func (cfg *Config) postgresConnString() string {
-	if cfg.Params == nil {
-		cfg.Params = map[string]string{}
-	}
+func (cfg *Config) postgresConnString(addr string, creds Credentials) string {
+	params := cfg.params()
 
-	if cfg.CertPath != "" {
-		cfg.Params["sslmode"] = "verify-full"
-		cfg.Params["sslcert"] = "/certs/client.postgres.crt"
-		cfg.Params["sslkey"] = "/certs/client.postgres.key"
-		cfg.Params["sslrootcert"] = "/certs/ca.crt"
+	if tlsCfg := cfg.tlsConfig(); tlsCfg != nil {
+		tlsCfg.postgresParams(params)
 	}
 
-	if len(cfg.Params) == 0 {
-		cfg.Params["sslmode"] = "disable"
+	if len(params) == 0 && !cfg.driverSSLMode {
+		params["sslmode"] = "disable"
 	}
 
-	addr := cfg.Addr
+	cfg.Timeouts.postgresParams(cfg.Type, params)
 
-	if len(cfg.Addrs) > 0 {
-		addr = cfg.Addrs[rand.Intn(len(cfg.Addrs))] //nolint:gosec // not a security issue.
+	user := url.User(creds.User)
+
+	if creds.Password != "" {
+		user = url.UserPassword(creds.User, creds.Password)
 	}
 
-	return fmt.Sprintf("postgres://%s@%s/%s%s", cfg.User, addr, cfg.Database, cfg.encodeParams())
+	return fmt.Sprintf("postgres://%s@%s/%s%s", user, addr, cfg.Database, encodeParams(params))
 }
 
-func (cfg *Config) clickhouseConnString() string {
-	if cfg.Params == nil {
-		cfg.Params = map[string]string{}
-	}
+func (cfg *Config) clickhouseConnString(addr string, creds Credentials) string {
+	params := cfg.params()
 
-	userParts := strings.Split(cfg.User, ":")
-
-	if len(userParts) > 1 {
-		cfg.Params["password"] = userParts[1]
+	if creds.Password != "" {
+		params["password"] = creds.Password
 	}
 
-	cfg.Params["username"] = userParts[0]
+	params["username"] = creds.User
 
 	if cfg.ReadTimeout != "" {
-		cfg.Params["read_timeout"] = cfg.ReadTimeout
+		params["read_timeout"] = cfg.ReadTimeout
 	}
 
 	if cfg.WriteTimeout != "" {
-		cfg.Params["write_timeout"] = cfg.WriteTimeout
+		params["write_timeout"] = cfg.WriteTimeout
 	}
 
-	return fmt.Sprintf("clickhouse://%s/%s%s", cfg.Addr, cfg.Database, cfg.encodeParams())
+	cfg.Timeouts.clickhouseParams(params)
+
+	if tlsCfg := cfg.tlsConfig(); tlsCfg != nil {
+		tlsCfg.clickhouseParams(params)
+	}
+
+	return fmt.Sprintf("clickhouse://%s/%s%s", addr, cfg.Database, encodeParams(params))
 }
 
 func (cfg *Config) sqliteConnString() string {
-	return fmt.Sprintf("%s%s", cfg.Database, cfg.encodeParams())
+	params := cfg.params()
+
+	cfg.Timeouts.sqliteParams(params)
+
+	return fmt.Sprintf("%s%s", cfg.Database, encodeParams(params))
+}
+
+// mysqlConnString returns go-sql-driver/mysql DSN. DATETIME and TIMESTAMP
+// columns are scanned to time.Time unless parseTime param is set.
+func (cfg *Config) mysqlConnString(addr string, creds Credentials) string {
+	params := cfg.params()
+
+	if _, ok := params["parseTime"]; !ok {
+		params["parseTime"] = "true"
+	}
+
+	cfg.Timeouts.mysqlParams(params)
+
+	if tlsCfg := cfg.tlsConfig(); tlsCfg != nil {
+		tlsCfg.mysqlParams(params)
+	}
+
+	user := creds.User
+
+	if creds.Password != "" {
+		user += ":" + creds.Password
+	}
+
+	return fmt.Sprintf("%s@tcp(%s)/%s%s", user, addr, cfg.Database, encodeParams(params))
+}
+
+// addr returns address of the database. For several addresses
+// a random one is returned.
+func (cfg *Config) addr() string {
+	if len(cfg.Addrs) > 0 {
+		return cfg.Addrs[rand.Intn(len(cfg.Addrs))] //nolint:gosec // not a security issue.
+	}
+
+	return cfg.Addr
+}
+
+// credentials returns static credentials from User.
+func (cfg *Config) credentials() Credentials {
+	user, password, _ := strings.Cut(cfg.User, ":")
+
+	return Credentials{User: user, Password: password}
+}
+
+// params returns copy of Params, so DSN builders don't modify config.
+func (cfg *Config) params() map[string]string {
+	params := make(map[string]string, len(cfg.Params))
+
+	for key, val := range cfg.Params {
+		params[key] = val
+	}
+
+	return params
+}
+
+// replicaConfig returns config of the read replica with addr.
+func (cfg *Config) replicaConfig(addr string) *Config {
+	replica := *cfg
+
+	replica.Addr = addr
+	replica.Addrs = nil
+	replica.ReplicaAddrs = nil
+	replica.Params = cfg.params()
+
+	return &replica
 }
 
 func (cfg *Config) driverName() string {
@@ -120,37 +226,43 @@ func (cfg *Config) hookConfig() *hooks.Config {
 		Type:           cfg.Type.String(),
 		ReadTimeout:    cfg.ReadTimeout,
 		WriteTimeout:   cfg.WriteTimeout,
-		DataSourceName: cfg.DSN(),
+		DataSourceName: cfg.RedactedDSN(),
 		DriverName:     cfg.driverName(),
 		Instance:       "-",
 	}
 }
 
-func (cfg *Config) encodeParams() string {
-	if len(cfg.Params) == 0 {
+// paramUnescaper restores slashes and colons escaped by url.QueryEscape,
+// they are allowed in query, so paths and addresses stay readable.
+var paramUnescaper = strings.NewReplacer("%2F", "/", "%3A", ":") //nolint:gochecknoglobals // constant replacer.
+
+// encodeParams returns query of params sorted by key. Keys and values
+// are escaped, so they are decoded as is by drivers and ParseDSN.
+func encodeParams(params map[string]string) string {
+	if len(params) == 0 {
 		return ""
 	}
 
 	var buf strings.Builder
 
-	keys := make([]string, 0, len(cfg.Params))
+	keys := make([]string, 0, len(params))
 
-	for key := range cfg.Params {
+	for key := range params {
 		keys = append(keys, key)
 	}
 
 	sort.Strings(keys)
 
 	for _, key := range keys {
-		val := cfg.Params[key]
+		val := params[key]
 
 		if buf.Len() > 0 {
 			buf.WriteByte('&')
 		}
 
-		buf.WriteString(key)
+		buf.WriteString(paramUnescaper.Replace(url.QueryEscape(key)))
 		buf.WriteByte('=')
-		buf.WriteString(val)
+		buf.WriteString(paramUnescaper.Replace(url.QueryEscape(val)))
 	}
 
 	return "?" + buf.String()
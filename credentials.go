@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoCredentials = errors.New("credentials not found")
+
+// Credentials are database user name and password.
+type Credentials struct {
+	User     string
+	Password string
+
+	// ExpiresAt is the time credentials expire.
+	// Zero value means credentials don't expire.
+	ExpiresAt time.Time
+}
+
+// CredentialsProvider provides credentials for new connections.
+// It is consulted whenever a connection is created, including connections
+// of the pool created on reconnect, so rotated passwords are used without
+// restart.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsProviderFunc is an adapter to use ordinary function as CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context) (Credentials, error)
+
+func (fn CredentialsProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return fn(ctx)
+}
+
+// FileCredentials reads credentials from files, e.g. mounted secrets or
+// files rendered by Vault agent. Files are read for every new connection.
+// Leading and trailing white space is trimmed.
+type FileCredentials struct {
+	// User is a user name. It is ignored if UserFile is set.
+	User     string
+	UserFile string
+
+	// PasswordFile is a path to file with password.
+	//
+	// This field is required and must be non-empty.
+	PasswordFile string
+}
+
+func (fc FileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	creds := Credentials{User: fc.User}
+
+	if fc.UserFile != "" {
+		user, err := os.ReadFile(fc.UserFile)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("read user file: %w", err)
+		}
+
+		creds.User = strings.TrimSpace(string(user))
+	}
+
+	if fc.PasswordFile == "" {
+		return Credentials{}, fmt.Errorf("%w: FileCredentials: PasswordFile required and must be non-empty", ErrInvalidConfig)
+	}
+
+	password, err := os.ReadFile(fc.PasswordFile)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("read password file: %w", err)
+	}
+
+	creds.Password = strings.TrimSpace(string(password))
+
+	return creds, nil
+}
+
+// EnvCredentials reads credentials from environment variables
+// for every new connection.
+type EnvCredentials struct {
+	// UserKey is an environment variable with user name. If it is empty,
+	// user name from Config.User is used.
+	UserKey string
+
+	// PasswordKey is an environment variable with password.
+	//
+	// This field is required and must be non-empty.
+	PasswordKey string
+}
+
+func (ec EnvCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	var creds Credentials
+
+	if ec.UserKey != "" {
+		user, ok := os.LookupEnv(ec.UserKey)
+		if !ok {
+			return Credentials{}, fmt.Errorf("%w: env %s is not set", ErrNoCredentials, ec.UserKey)
+		}
+
+		creds.User = user
+	}
+
+	if ec.PasswordKey == "" {
+		return Credentials{}, fmt.Errorf("%w: EnvCredentials: PasswordKey required and must be non-empty", ErrInvalidConfig)
+	}
+
+	password, ok := os.LookupEnv(ec.PasswordKey)
+	if !ok {
+		return Credentials{}, fmt.Errorf("%w: env %s is not set", ErrNoCredentials, ec.PasswordKey)
+	}
+
+	creds.Password = password
+
+	return creds, nil
+}
+
+type cachedCredentials struct {
+	provider      CredentialsProvider
+	refreshBefore time.Duration
+	ttl           time.Duration
+
+	mu         sync.Mutex
+	creds      Credentials
+	validUntil time.Time
+	cached     bool
+	inflight   *credentialsCall
+}
+
+type credentialsCall struct {
+	done  chan struct{}
+	creds Credentials
+	err   error
+}
+
+// NewCachedCredentials returns provider that caches credentials of provider.
+// Credentials are refreshed refreshBefore they expire, if refresh fails
+// cached credentials are used until expiry. Credentials without expiry
+// are cached for ttl, zero ttl disables caching of such credentials.
+//
+// Only one refresh runs at a time, cached credentials that are not expired
+// are returned to other callers until it is finished.
+func NewCachedCredentials(provider CredentialsProvider, refreshBefore, ttl time.Duration) CredentialsProvider {
+	return &cachedCredentials{
+		provider:      provider,
+		refreshBefore: refreshBefore,
+		ttl:           ttl,
+	}
+}
+
+func (cc *cachedCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	cc.mu.Lock()
+
+	now := time.Now()
+
+	if cc.cached && now.Before(cc.validUntil) {
+		creds := cc.creds
+		cc.mu.Unlock()
+
+		return creds, nil
+	}
+
+	if call := cc.inflight; call != nil {
+		creds, usable := cc.creds, cc.usable(now)
+		cc.mu.Unlock()
+
+		if usable {
+			return creds, nil
+		}
+
+		select {
+		case <-call.done:
+			return call.creds, call.err
+		case <-ctx.Done():
+			return Credentials{}, ctx.Err()
+		}
+	}
+
+	call := &credentialsCall{done: make(chan struct{})}
+	cc.inflight = call
+
+	cc.mu.Unlock()
+
+	call.creds, call.err = cc.refresh(ctx)
+
+	close(call.done)
+
+	return call.creds, call.err
+}
+
+// refresh requests credentials from provider without lock, so cached
+// credentials are available meanwhile.
+func (cc *cachedCredentials) refresh(ctx context.Context) (Credentials, error) {
+	creds, err := cc.provider.Credentials(ctx)
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.inflight = nil
+
+	now := time.Now()
+
+	if err != nil {
+		if cc.usable(now) {
+			return cc.creds, nil
+		}
+
+		return Credentials{}, err
+	}
+
+	cc.creds, cc.cached = creds, true
+
+	if creds.ExpiresAt.IsZero() {
+		cc.validUntil = now.Add(cc.ttl)
+	} else {
+		cc.validUntil = creds.ExpiresAt.Add(-cc.refreshBefore)
+	}
+
+	return creds, nil
+}
+
+// usable reports whether cached credentials are not expired.
+func (cc *cachedCredentials) usable(now time.Time) bool {
+	return cc.cached && (cc.creds.ExpiresAt.IsZero() || now.Before(cc.creds.ExpiresAt))
+}
@@ -0,0 +1,261 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCredentials_Credentials(t *testing.T) {
+	var (
+		dir          = t.TempDir()
+		userFile     = filepath.Join(dir, "user")
+		passwordFile = filepath.Join(dir, "password")
+	)
+
+	require.NoError(t, os.WriteFile(userFile, []byte("file-user\n"), 0o600))
+	require.NoError(t, os.WriteFile(passwordFile, []byte(" secret\n"), 0o600))
+
+	tests := []struct {
+		name     string
+		provider FileCredentials
+		want     Credentials
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "pass",
+			provider: FileCredentials{User: "user", PasswordFile: passwordFile},
+			want:     Credentials{User: "user", Password: "secret"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "pass user file",
+			provider: FileCredentials{User: "user", UserFile: userFile, PasswordFile: passwordFile},
+			want:     Credentials{User: "file-user", Password: "secret"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "empty password file",
+			provider: FileCredentials{User: "user"},
+			wantErr:  assert.Error,
+		},
+		{
+			name:     "password file not exists",
+			provider: FileCredentials{PasswordFile: filepath.Join(dir, "not-exists")},
+			wantErr:  assert.Error,
+		},
+		{
+			name:     "user file not exists",
+			provider: FileCredentials{UserFile: filepath.Join(dir, "not-exists"), PasswordFile: passwordFile},
+			wantErr:  assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.Credentials(context.Background())
+			if !tt.wantErr(t, err, "Credentials()") {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEnvCredentials_Credentials(t *testing.T) {
+	t.Setenv("TEST_DB_USER", "env-user")
+	t.Setenv("TEST_DB_PASSWORD", "secret")
+
+	tests := []struct {
+		name     string
+		provider EnvCredentials
+		want     Credentials
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "pass",
+			provider: EnvCredentials{UserKey: "TEST_DB_USER", PasswordKey: "TEST_DB_PASSWORD"},
+			want:     Credentials{User: "env-user", Password: "secret"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "pass without user",
+			provider: EnvCredentials{PasswordKey: "TEST_DB_PASSWORD"},
+			want:     Credentials{Password: "secret"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "empty password key",
+			provider: EnvCredentials{UserKey: "TEST_DB_USER"},
+			wantErr:  assert.Error,
+		},
+		{
+			name:     "password not set",
+			provider: EnvCredentials{PasswordKey: "TEST_DB_NOT_SET"},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrNoCredentials, i...)
+			},
+		},
+		{
+			name:     "user not set",
+			provider: EnvCredentials{UserKey: "TEST_DB_NOT_SET", PasswordKey: "TEST_DB_PASSWORD"},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrNoCredentials, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.Credentials(context.Background())
+			if !tt.wantErr(t, err, "Credentials()") {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewCachedCredentials(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		calls   int
+		expires time.Time
+		errGet  error
+	)
+
+	provider := NewCachedCredentials(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		calls++
+
+		return Credentials{User: "user", ExpiresAt: expires}, errGet
+	}), time.Minute, 0)
+
+	// Credentials valid for an hour are cached.
+	expires = time.Now().Add(time.Hour)
+
+	for i := 0; i < 3; i++ {
+		_, err := provider.Credentials(ctx)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, calls)
+
+	// Credentials are refreshed before expiry.
+	provider.(*cachedCredentials).validUntil = time.Now()
+
+	_, err := provider.Credentials(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Cached credentials are used if refresh fails before expiry.
+	provider.(*cachedCredentials).validUntil = time.Now()
+	errGet = errors.New("unavailable")
+
+	got, err := provider.Credentials(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "user", got.User)
+	assert.Equal(t, 3, calls)
+
+	// Expired credentials are not used.
+	provider.(*cachedCredentials).creds.ExpiresAt = time.Now().Add(-time.Second)
+
+	_, err = provider.Credentials(ctx)
+	assert.ErrorIs(t, err, errGet)
+}
+
+func TestNewCachedCredentials_concurrentRefresh(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		calls   atomic.Int64
+		release = make(chan struct{})
+		started = make(chan struct{}, 1)
+	)
+
+	provider := NewCachedCredentials(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		if calls.Add(1) > 1 {
+			started <- struct{}{}
+			<-release
+		}
+
+		return Credentials{User: "user", Password: fmt.Sprint(calls.Load()), ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}), time.Minute, 0)
+
+	_, err := provider.Credentials(ctx)
+	require.NoError(t, err)
+
+	// Refresh is due, the first caller requests credentials.
+	provider.(*cachedCredentials).mu.Lock()
+	provider.(*cachedCredentials).validUntil = time.Now()
+	provider.(*cachedCredentials).mu.Unlock()
+
+	refreshed := make(chan Credentials, 1)
+
+	go func() {
+		creds, err := provider.Credentials(ctx)
+		assert.NoError(t, err)
+
+		refreshed <- creds
+	}()
+
+	<-started
+
+	// Other callers get cached credentials until the refresh is finished.
+	got, err := provider.Credentials(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", got.Password)
+
+	close(release)
+
+	assert.Equal(t, "2", (<-refreshed).Password)
+	assert.Equal(t, int64(2), calls.Load())
+
+	got, err = provider.Credentials(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2", got.Password)
+}
+
+func TestNewCachedCredentials_waitRefresh(t *testing.T) {
+	var (
+		calls   atomic.Int64
+		release = make(chan struct{})
+		started = make(chan struct{})
+	)
+
+	provider := NewCachedCredentials(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		calls.Add(1)
+		close(started)
+		<-release
+
+		return Credentials{User: "user"}, nil
+	}), 0, time.Hour)
+
+	done := make(chan Credentials, 1)
+
+	go func() {
+		creds, _ := provider.Credentials(context.Background())
+
+		done <- creds
+	}()
+
+	<-started
+
+	// Without cached credentials other callers wait for the refresh.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := provider.Credentials(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+
+	assert.Equal(t, "user", (<-done).User)
+	assert.Equal(t, int64(1), calls.Load())
+}
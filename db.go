@@ -2,10 +2,9 @@ package database
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
-	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -16,20 +15,34 @@ import (
 )
 
 const (
-	_withHookDriverName = "%s-with-hook-%s"
-	_txSpanName         = "SQL Tx"
-	_defaultTracerName  = "github.com/loghole/database"
+	_txSpanName        = "SQL Tx"
+	_defaultTracerName = "github.com/loghole/database"
 )
 
 var (
 	ErrMaxRetryAttempts = errors.New("max retry attempts has been reached")
 	ErrInvalidConfig    = errors.New("invalid config")
+
+	ErrReconnectThrottled = errors.New("reconnect throttled")
+	ErrShutdown           = errors.New("database is shut down")
+	ErrNotSupported       = errors.New("not supported")
 )
 
 type DB struct {
-	DB       *sqlx.DB
 	hooksCfg *hooks.Config
 	baseCfg  *Config
+	pool     *connPool
+	hook     dbhook.Hook
+	metrics  *metricCollectors
+
+	reconnector *reconnector
+	addrs       *addrSet
+	node        atomic.Pointer[poolNode]
+	rebalancer  *periodic
+	poolStats   *poolStatsExporter
+	replicas    *replicaSet
+	health      *healthChecker
+	tracker     *tracker
 
 	options options
 }
@@ -40,46 +53,114 @@ type (
 	RetryFunc       func(retryCount int, err error) bool
 )
 
-func New(cfg *Config, opts ...Option) (db *DB, err error) {
+// New creates DB and connects to the database according to StartupPolicy.
+func New(cfg *Config, opts ...Option) (*DB, error) {
+	return NewContext(context.Background(), cfg, opts...)
+}
+
+// NewContext creates DB like New. The context bounds the initial
+// connect including all retries.
+func NewContext(ctx context.Context, cfg *Config, opts ...Option) (db *DB, err error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	db = &DB{
 		baseCfg:  cfg,
 		hooksCfg: cfg.hookConfig(),
+		pool:     newConnPool(cfg),
+		tracker:  newTracker(),
+		options:  defaultOptions(),
 	}
 
 	if err := db.options.apply(db.hooksCfg, opts...); err != nil {
 		return nil, fmt.Errorf("apply options: %w", err)
 	}
 
-	db.hooksCfg.DriverName, err = wrapDriver(cfg.driverName(), db.options.hook())
-	if err != nil {
-		return nil, fmt.Errorf("wrap driver: %w", err)
+	if err := db.options.validate(cfg); err != nil {
+		return nil, err
 	}
 
-	db.DB, err = dbsqlx.NewSQLx(db.hooksCfg.DriverName, cfg.DSN())
+	db.hook = db.options.hook()
+	db.metrics = newMetricCollectors(db.hooksCfg, db.options.collectors)
+	db.pool.externalIdle = db.options.pgxPool != nil
+	db.pool.trackPending = db.options.trackPending()
+	db.addrs = newAddrSet(cfg, db.options.addrPolicy)
+	db.hooksCfg.Node = &hooks.Node{}
+	db.reconnector = newReconnector(db.options.reconnectPolicy, db.replacePool)
+	db.hooksCfg.ReconnectContextFn = db.reconnectContext
+
+	// The initial connect is retried by StartupPolicy, errors of its
+	// queries are returned as is.
+	sqlxDB, err := db.connect(hooks.WithoutReconnect(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("new db: %w", err)
 	}
 
-	db.hooksCfg.Instance = getDBIInstance(db.DB)
-	db.hooksCfg.ReconnectFn = db.reconnect
+	db.pool.replace(sqlxDB)
+
+	if !db.options.startup.Lazy {
+		db.discoverInstance(ctx, sqlxDB)
+	}
+
+	if len(cfg.ReplicaAddrs) > 0 {
+		if db.replicas, err = newReplicas(ctx, cfg, db.options, opts); err != nil {
+			_ = sqlxDB.Close()
+
+			return nil, fmt.Errorf("new replicas: %w", err)
+		}
+	}
+
+	db.startHealthCheck()
+	db.startRebalance()
+	db.startPoolStats()
 
 	return db, nil
 }
 
+// SQLx returns current underlying connection pool.
+//
+// The pool is replaced on reconnect, so the returned value
+// should not be stored for later use.
+func (db *DB) SQLx() *sqlx.DB {
+	return db.pool.load()
+}
+
 // Close closes the database and prevents new queries from starting.
 // Close then waits for all queries that have started processing on the server
 // to finish.
 //
 // It is rare to Close a DB, as the DB handle is meant to be
 // long-lived and shared between many goroutines.
+//
+// Close also closes connections to read replicas. New operations
+// are rejected with ErrShutdown, use Shutdown to wait for running ones.
 func (db *DB) Close() error {
-	return db.DB.Close()
+	db.tracker.close()
+
+	if db.health != nil {
+		db.health.close()
+	}
+
+	if db.rebalancer != nil {
+		db.rebalancer.close()
+	}
+
+	if db.poolStats != nil {
+		db.poolStats.close()
+	}
+
+	if db.replicas != nil {
+		return errors.Join(db.SQLx().Close(), db.replicas.close())
+	}
+
+	return db.SQLx().Close()
 }
 
 // PingContext verifies a connection to the database is still alive,
 // establishing a connection if necessary.
 func (db *DB) PingContext(ctx context.Context) error {
-	return db.DB.PingContext(ctx)
+	return dbsqlx.Ping(ctx, db.SQLx())
 }
 
 // SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
@@ -87,8 +168,21 @@ func (db *DB) PingContext(ctx context.Context) error {
 // Expired connections may be closed lazily before reuse.
 //
 // If d <= 0, connections are not closed due to a connection's idle time.
+//
+// The value is also applied to every pool created on reconnect.
 func (db *DB) SetConnMaxIdleTime(d time.Duration) {
-	db.DB.SetConnMaxIdleTime(d)
+	db.pool.setConnMaxIdleTime(d)
+}
+
+// SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
+//
+// Expired connections may be closed lazily before reuse.
+//
+// If d <= 0, connections are not closed due to a connection's age.
+//
+// The value is also applied to every pool created on reconnect.
+func (db *DB) SetConnMaxLifetime(d time.Duration) {
+	db.pool.setConnMaxLifetime(d)
 }
 
 // SetMaxIdleConns sets the maximum number of connections in the idle
@@ -101,8 +195,11 @@ func (db *DB) SetConnMaxIdleTime(d time.Duration) {
 //
 // The default max idle connections is currently 2. This may change in
 // a future release.
+//
+// The value is also applied to every pool created on reconnect.
+// It has no effect with WithPGXPool, idle connections are kept by pgxpool.
 func (db *DB) SetMaxIdleConns(n int) {
-	db.DB.SetMaxIdleConns(n)
+	db.pool.setMaxIdleConns(n)
 }
 
 // SetMaxOpenConns sets the maximum number of open connections to the database.
@@ -113,51 +210,239 @@ func (db *DB) SetMaxIdleConns(n int) {
 //
 // If n <= 0, then there is no limit on the number of open connections.
 // The default is 0 (unlimited).
+//
+// The value is also applied to every pool created on reconnect.
 func (db *DB) SetMaxOpenConns(n int) {
-	db.DB.SetMaxOpenConns(n)
+	db.pool.setMaxOpenConns(n)
 }
 
-func wrapDriver(driverName string, hook dbhook.Hook) (string, error) {
-	const base = 36
+// connect opens the initial connection pool according to StartupPolicy.
+func (db *DB) connect(ctx context.Context) (*sqlx.DB, error) {
+	policy := db.options.startup
+
+	if policy.Lazy {
+		node := &poolNode{addr: db.addrs.candidates()[0]}
+
+		sqlxDB, err := dbsqlx.Open(node.config(db.connectorConfig(node.addr)))
+		if err != nil {
+			return nil, err //nolint:wrapcheck // need clean err.
+		}
 
-	if hook == nil { // skip wrapping for empty hook.
-		return driverName, nil
+		db.useNode(node)
+
+		return sqlxDB, nil
+	}
+
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	if policy.Retry == nil {
+		return db.openPool(ctx)
 	}
 
-	// Open db for get original driver.
-	db, err := sql.Open(driverName, "")
+	var (
+		sqlxDB  *sqlx.DB
+		lastErr error
+	)
+
+	err := retry(ctx, policy.Retry, func() (err error) {
+		sqlxDB, err = db.openPool(ctx)
+		if err != nil {
+			lastErr = err
+		}
+
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("can't find original driver: %w", err)
+		if lastErr != nil && !errors.Is(err, lastErr) {
+			return nil, fmt.Errorf("%w: %w", err, lastErr)
+		}
+
+		return nil, err
+	}
+
+	return sqlxDB, nil
+}
+
+// openPool opens pool to the node selected by AddrPolicy. If connect fails,
+// the node is excluded and the next one is tried.
+func (db *DB) openPool(ctx context.Context) (*sqlx.DB, error) {
+	var errs []error
+
+	for _, addr := range db.addrs.candidates() {
+		node := &poolNode{addr: addr}
+
+		sqlxDB, err := dbsqlx.NewSQLx(ctx, node.config(db.connectorConfig(addr)))
+		if err == nil {
+			db.useNode(node)
+
+			return sqlxDB, nil
+		}
+
+		db.addrs.fail(addr)
+
+		if errs = append(errs, err); ctx.Err() != nil {
+			break
+		}
+	}
+
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// useNode records node of the new pool.
+func (db *DB) useNode(node *poolNode) {
+	db.node.Store(node)
+	db.addrs.use(node.addr)
+	db.hooksCfg.Node.SetAddr(node.addr)
+}
+
+// connectorConfig returns config of connector for a new pool. All connections
+// of the pool use the same address, credentials are requested from
+// Config.Credentials for every new connection.
+func (db *DB) connectorConfig(addr string) dbsqlx.Config {
+	var (
+		cfg = dbsqlx.Config{
+			DriverName: db.hooksCfg.DriverName,
+			Hook:       db.hook,
+		}
+		provider = db.baseCfg.Credentials
+	)
+
+	if db.options.pgxPool != nil {
+		// Pool connections are initialized and authenticated by pgxpool.
+		cfg.DataSourceName = db.baseCfg.dsn(addr, db.staticCredentials())
+		cfg.OpenConnector = db.pgxPoolConnector
+
+		return cfg
+	}
+
+	for _, fn := range db.onConnect() {
+		fn := fn
+
+		cfg.OnConnect = append(cfg.OnConnect, func(ctx context.Context, session *dbsqlx.Session) error {
+			return fn(ctx, session)
+		})
 	}
 
-	defer db.Close()
+	if tlsCfg := db.baseCfg.tlsConfig(); tlsCfg != nil {
+		switch {
+		case db.baseCfg.Type == PGXDatabase && tlsCfg.inMemory():
+			cfg.OpenConnector = tlsCfg.pgxConnector
+		case db.baseCfg.Type == MySQLDatabase && tlsCfg.mysqlCustom():
+			cfg.OpenConnector = tlsCfg.mysqlConnector
+		case db.baseCfg.Type == ClickhouseDatabase && tlsCfg.clickhouseCustom():
+			cfg.OpenConnector = tlsCfg.clickhouseConnector(db.options.clickhouseConnector)
+		}
+	}
+
+	if provider == nil {
+		cfg.DataSourceName = db.baseCfg.dsn(addr, db.staticCredentials())
+
+		return cfg
+	}
+
+	cfg.DataSourceFunc = func(ctx context.Context) (string, error) {
+		creds, err := provider.Credentials(ctx)
+		if err != nil {
+			return "", fmt.Errorf("get credentials: %w", err)
+		}
 
-	newDriverName := fmt.Sprintf(_withHookDriverName, driverName, strconv.FormatInt(time.Now().UnixNano(), base))
+		if creds.User == "" {
+			creds.User = db.baseCfg.credentials().User
+		}
 
-	// Register wrapped driver with new name for open it later.
-	sql.Register(newDriverName, dbhook.Wrap(db.Driver(), hook))
+		return db.baseCfg.dsn(addr, creds), nil
+	}
 
-	return newDriverName, nil
+	return cfg
 }
 
+// onConnect returns callbacks for a new connection: session settings
+// of the database type and callbacks of WithOnConnect.
+func (db *DB) onConnect() []OnConnectFunc {
+	if db.baseCfg.Type == CockroachDatabase {
+		if session := db.baseCfg.Timeouts.cockroachSession(); session != nil {
+			return append([]OnConnectFunc{session}, db.options.onConnect...)
+		}
+	}
+
+	return db.options.onConnect
+}
+
+// staticCredentials returns credentials from Config.User. Password is
+// omitted if credentials are requested from Config.Credentials.
+func (db *DB) staticCredentials() Credentials {
+	creds := db.baseCfg.credentials()
+
+	if db.baseCfg.Credentials != nil {
+		creds.Password = ""
+	}
+
+	return creds
+}
+
+// reconnect replaces connection pool. Concurrent calls are collapsed
+// into a single attempt and attempts are limited by ReconnectPolicy.
+// The node in use is excluded, so the new pool is opened to other node.
 func (db *DB) reconnect() error {
-	tmpSQLx, err := dbsqlx.NewSQLx(db.hooksCfg.DriverName, db.baseCfg.DSN())
-	if err != nil {
-		return fmt.Errorf("new db: %w", err)
+	return db.reconnector.reconnect(func() bool {
+		db.addrs.failCurrent()
+
+		return true
+	})
+}
+
+// reconnectContext replaces connection pool after connection error of
+// the query with ctx. Only node of the pool the query was sent to is
+// excluded. If the pool was already replaced, the query is retried
+// with the new pool without reconnect.
+func (db *DB) reconnectContext(ctx context.Context) error {
+	node, ok := ctx.Value(poolNodeContextKey{}).(*poolNode)
+	if !ok {
+		return db.reconnect()
 	}
 
-	oldDB := *db.DB
-	*db.DB = *tmpSQLx
+	return db.reconnector.reconnect(func() bool {
+		if node != db.node.Load() {
+			return false
+		}
 
-	go oldDB.Close()
+		db.addrs.fail(node.addr)
 
-	return nil
+		return true
+	})
 }
 
-func getDBIInstance(db *sqlx.DB) string {
-	var nodeID int
+// replacePool opens new pool and replaces current one,
+// it is called by reconnector for every reconnect attempt.
+func (db *DB) replacePool() (err error) {
+	defer func() { db.metrics.reconnect(err) }()
 
-	_ = db.Get(&nodeID, `SHOW node_id`)
+	// Queries of the new pool must not reconnect,
+	// they would wait for this attempt.
+	ctx := hooks.WithoutReconnect(context.Background())
 
-	return strconv.Itoa(nodeID)
+	sqlxDB, err := db.openPool(ctx)
+	if err != nil {
+		return fmt.Errorf("new db: %w", err)
+	}
+
+	db.discoverInstance(ctx, sqlxDB)
+
+	if old := db.pool.replace(sqlxDB); old != nil {
+		go func() {
+			drain(old, db.options.reconnectPolicy.DrainTimeout)
+			db.pool.retire(old)
+		}()
+	}
+
+	return nil
 }
@@ -2,11 +2,20 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/loghole/dbhook"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.13.0"
+
+	"github.com/loghole/database/dberrors"
 )
 
 func TestNew(t *testing.T) {
@@ -56,6 +65,252 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_driverRegistration(t *testing.T) {
+	var (
+		cfg    = &Config{Database: ":memory:", Type: SQLiteDatabase}
+		before = sql.Drivers()
+		called int
+	)
+
+	hook := &countHook{fn: func() { called++ }}
+
+	for i := 0; i < 3; i++ {
+		db, err := New(cfg, WithCustomHook(hook))
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(context.Background(), "SELECT 1")
+		require.NoError(t, err)
+
+		require.NoError(t, db.Close())
+	}
+
+	assert.Equal(t, before, sql.Drivers(), "drivers must not be registered")
+	assert.Positive(t, called, "hook must be called")
+}
+
+type countHook struct {
+	fn func()
+}
+
+func (h *countHook) Before(ctx context.Context, _ *dbhook.HookInput) (context.Context, error) {
+	h.fn()
+
+	return ctx, nil
+}
+
+func (h *countHook) After(ctx context.Context, _ *dbhook.HookInput) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *countHook) Error(ctx context.Context, input *dbhook.HookInput) (context.Context, error) {
+	return ctx, input.Error
+}
+
+func TestWithOnConnect(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		tracer   = tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)).Tracer("")
+		ctx      = context.Background()
+		cfg      = &Config{Database: ":memory:", Type: SQLiteDatabase}
+		called   int
+	)
+
+	db, err := New(cfg, WithTracingHook(tracer), WithOnConnect(func(ctx context.Context, conn SessionConn) error {
+		called++
+
+		_, err := conn.ExecContext(ctx, "PRAGMA foreign_keys=ON")
+
+		return err
+	}))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	db.SetMaxOpenConns(1)
+
+	var enabled int
+
+	require.NoError(t, db.GetContext(ctx, &enabled, "PRAGMA foreign_keys"))
+	assert.Equal(t, 1, enabled)
+	assert.Equal(t, 1, called)
+
+	require.NoError(t, db.reconnect())
+	require.NoError(t, db.GetContext(ctx, &enabled, "PRAGMA foreign_keys"))
+	assert.Equal(t, 1, enabled)
+	assert.Equal(t, 2, called, "must run for connections after reconnect")
+
+	var traced int
+
+	for _, span := range recorder.Ended() {
+		for _, attr := range span.Attributes() {
+			if attr.Key == semconv.DBStatementKey && attr.Value.AsString() == "PRAGMA foreign_keys=ON" {
+				traced++
+			}
+		}
+	}
+
+	assert.Equal(t, 2, traced)
+
+	errInit := errors.New("init error")
+
+	_, err = New(cfg, WithOnConnect(func(ctx context.Context, conn SessionConn) error {
+		return errInit
+	}))
+	assert.ErrorIs(t, err, errInit)
+	assert.ErrorIs(t, err, dberrors.ErrConnectionInit)
+	assert.True(t, dberrors.IsConnectionFailure(err))
+
+	_, err = New(cfg, WithOnConnect(nil))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfig_Credentials(t *testing.T) {
+	var (
+		calls int
+		err   error
+	)
+
+	cfg := &Config{
+		Database: ":memory:",
+		Type:     SQLiteDatabase,
+		Credentials: CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+			calls++
+
+			return Credentials{User: "user", Password: "password"}, err
+		}),
+	}
+
+	db, err := New(cfg)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	assert.Equal(t, 1, calls)
+
+	require.NoError(t, db.reconnect())
+	require.NoError(t, db.PingContext(context.Background()))
+	assert.Equal(t, 2, calls, "must be consulted after reconnect")
+
+	err = errors.New("unavailable")
+
+	_, err = New(cfg)
+	assert.ErrorIs(t, err, dberrors.ErrConnectionInit)
+}
+
+func TestNewContext_startup(t *testing.T) {
+	var (
+		unreachable = &Config{Database: "/not/exists/dir/test.db", Type: SQLiteDatabase}
+		attempts    int
+	)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		opts     []Option
+		wantErr  assert.ErrorAssertionFunc
+		attempts int
+	}{
+		{
+			name:    "fail without retry",
+			ctx:     context.Background(),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "lazy",
+			ctx:     context.Background(),
+			opts:    []Option{WithLazyConnect()},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "retry attempts",
+			ctx:  context.Background(),
+			opts: []Option{WithStartupPolicy(StartupPolicy{
+				Retry: &RetryPolicy{
+					MaxAttempts:       3,
+					InitialBackoff:    time.Millisecond,
+					MaxBackoff:        time.Millisecond,
+					BackoffMultiplier: 1,
+					ErrIsRetryable:    func(err error) bool { attempts++; return true },
+				},
+			})},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrMaxRetryAttempts, i...)
+			},
+			attempts: 3,
+		},
+		{
+			name: "retry ctx canceled",
+			ctx:  contextCanceled(),
+			opts: []Option{WithStartupPolicy(StartupPolicy{
+				Retry: &RetryPolicy{
+					MaxAttempts:       100,
+					InitialBackoff:    time.Second,
+					MaxBackoff:        time.Second,
+					BackoffMultiplier: 1,
+				},
+			})},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, context.Canceled, i...)
+			},
+		},
+		{
+			name: "retry timeout",
+			ctx:  context.Background(),
+			opts: []Option{WithStartupPolicy(StartupPolicy{
+				Timeout: 50 * time.Millisecond,
+				Retry: &RetryPolicy{
+					MaxAttempts:       100,
+					InitialBackoff:    time.Second,
+					MaxBackoff:        time.Second,
+					BackoffMultiplier: 1,
+				},
+			})},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, context.DeadlineExceeded, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts = 0
+
+			db, err := NewContext(tt.ctx, unreachable, tt.opts...)
+			if !tt.wantErr(t, err, "NewContext()") {
+				return
+			}
+
+			assert.Equal(t, tt.attempts, attempts)
+
+			if err != nil {
+				return
+			}
+
+			defer db.Close()
+
+			assert.Error(t, db.PingContext(context.Background()))
+		})
+	}
+}
+
+func TestNew_mysql(t *testing.T) {
+	db, err := New(&Config{
+		Addr:     "127.0.0.1:3306",
+		User:     "root",
+		Database: "database",
+		Type:     MySQLDatabase,
+	}, WithLazyConnect())
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	assert.Equal(t, "mysql", db.SQLx().DriverName())
+
+	query, args, err := db.BindNamed("SELECT * FROM users WHERE id = :id", map[string]interface{}{"id": 1})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", query)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
 func TestDB_PingContext(t *testing.T) {
 	type args struct {
 		ctx context.Context
@@ -113,6 +368,30 @@ func TestDB_SetConnMaxIdleTime(t *testing.T) {
 	}
 }
 
+func TestDB_SetConnMaxLifetime(t *testing.T) {
+	type args struct {
+		d time.Duration
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{
+			name: "pass",
+			args: args{
+				time.Second,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := memorySQLLite(t)
+
+			db.SetConnMaxLifetime(tt.args.d)
+		})
+	}
+}
+
 func TestDB_SetMaxIdleConns(t *testing.T) {
 	type args struct {
 		n int
@@ -161,6 +440,59 @@ func TestDB_SetMaxOpenConns(t *testing.T) {
 	}
 }
 
+func TestDB_reconnectPoolSettings(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          *Config
+		setup        func(db *DB)
+		wantMaxConns int
+	}{
+		{
+			name: "from config",
+			cfg: &Config{
+				Database:        ":memory:",
+				Type:            SQLiteDatabase,
+				MaxOpenConns:    3,
+				MaxIdleConns:    2,
+				ConnMaxLifetime: time.Minute,
+				ConnMaxIdleTime: time.Second,
+			},
+			wantMaxConns: 3,
+		},
+		{
+			name: "from setters",
+			cfg: &Config{
+				Database:     ":memory:",
+				Type:         SQLiteDatabase,
+				MaxOpenConns: 3,
+			},
+			setup: func(db *DB) {
+				db.SetMaxOpenConns(5)
+				db.SetMaxIdleConns(1)
+			},
+			wantMaxConns: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := New(tt.cfg)
+			require.NoError(t, err)
+
+			defer db.Close()
+
+			if tt.setup != nil {
+				tt.setup(db)
+			}
+
+			assert.Equal(t, tt.wantMaxConns, db.SQLx().Stats().MaxOpenConnections, "before reconnect")
+
+			require.NoError(t, db.reconnect())
+
+			assert.Equal(t, tt.wantMaxConns, db.SQLx().Stats().MaxOpenConnections, "after reconnect")
+		})
+	}
+}
+
 func contextCanceled() context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -0,0 +1,55 @@
+package dberrors
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ClickHouse exception codes.
+// https://github.com/ClickHouse/ClickHouse/blob/master/src/Common/ErrorCodes.cpp
+const (
+	_chTimeoutExceeded    = 159
+	_chSocketTimeout      = 209
+	_chNetworkError       = 210
+	_chQueryWasCancelled  = 394
+	_chViolatedConstraint = 469
+	_chDeadlockAvoided    = 473
+)
+
+// ClassifyClickHouse returns class of clickhouse-go exception.
+func ClassifyClickHouse(err error) Class {
+	code, ok := ClickHouseCode(err)
+	if !ok {
+		return Unknown
+	}
+
+	switch code {
+	case _chTimeoutExceeded, _chQueryWasCancelled:
+		return QueryCanceled
+	case _chSocketTimeout, _chNetworkError:
+		return ConnectionFailure
+	case _chViolatedConstraint:
+		return CheckViolation
+	case _chDeadlockAvoided:
+		return Deadlock
+	default:
+		return Unknown
+	}
+}
+
+// ClickHouseCode returns code of clickhouse-go v1 or v2 exception.
+// Exception type is matched by reflection so as not to require the driver.
+func ClickHouseCode(err error) (int64, bool) {
+	val, ok := findStruct(err, func(typ reflect.Type) bool {
+		return strings.HasPrefix(typ.PkgPath(), "github.com/ClickHouse/clickhouse-go") && typ.Name() == "Exception"
+	})
+	if !ok {
+		return 0, false
+	}
+
+	if field := val.FieldByName("Code"); field.IsValid() && field.CanInt() {
+		return field.Int(), true
+	}
+
+	return 0, false
+}
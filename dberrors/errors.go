@@ -0,0 +1,138 @@
+// Package dberrors classifies errors returned by database drivers.
+//
+// Supported drivers: lib/pq, pgx v5, go-sqlite3, clickhouse-go and
+// go-sql-driver/mysql.
+package dberrors
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// Class is a kind of database error.
+type Class string
+
+func (c Class) String() string { return string(c) }
+
+const (
+	Unknown              Class = "unknown"
+	SerializationFailure Class = "serialization_failure"
+	Deadlock             Class = "deadlock"
+	UniqueViolation      Class = "unique_violation"
+	ForeignKeyViolation  Class = "foreign_key_violation"
+	NotNullViolation     Class = "not_null_violation"
+	CheckViolation       Class = "check_violation"
+	LockTimeout          Class = "lock_timeout"
+	QueryCanceled        Class = "query_canceled"
+	ConnectionFailure    Class = "connection_failure"
+)
+
+// ErrConnectionInit is returned when initialization of a new connection
+// fails. It is classified as ConnectionFailure.
+var ErrConnectionInit = errors.New("connection initialization failed")
+
+// Classify returns class of the error. It returns Unknown for nil error
+// and for errors that are not recognized.
+func Classify(err error) Class {
+	if err == nil {
+		return Unknown
+	}
+
+	// Canceled and timed out contexts of the caller wrap network errors of
+	// some drivers, so they are checked first.
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return QueryCanceled
+	}
+
+	if errors.Is(err, ErrConnectionInit) {
+		return ConnectionFailure
+	}
+
+	for _, classify := range []func(err error) Class{
+		ClassifyPostgres,
+		ClassifySQLite,
+		ClassifyClickHouse,
+		ClassifyMySQL,
+	} {
+		if class := classify(err); class != Unknown {
+			return class
+		}
+	}
+
+	if IsNetworkError(err) {
+		return ConnectionFailure
+	}
+
+	return Unknown
+}
+
+// IsSerializationFailure reports whether the transaction was aborted
+// because of concurrent update and may be retried.
+func IsSerializationFailure(err error) bool { return Classify(err) == SerializationFailure }
+
+// IsDeadlock reports whether the transaction was aborted by deadlock detection.
+func IsDeadlock(err error) bool { return Classify(err) == Deadlock }
+
+// IsUniqueViolation reports whether the error is unique or primary key violation.
+func IsUniqueViolation(err error) bool { return Classify(err) == UniqueViolation }
+
+// IsForeignKeyViolation reports whether the error is foreign key violation.
+func IsForeignKeyViolation(err error) bool { return Classify(err) == ForeignKeyViolation }
+
+// IsNotNullViolation reports whether the error is not null violation.
+func IsNotNullViolation(err error) bool { return Classify(err) == NotNullViolation }
+
+// IsCheckViolation reports whether the error is check constraint violation.
+func IsCheckViolation(err error) bool { return Classify(err) == CheckViolation }
+
+// IsLockTimeout reports whether a lock could not be acquired in time.
+func IsLockTimeout(err error) bool { return Classify(err) == LockTimeout }
+
+// IsQueryCanceled reports whether the query was canceled by user request,
+// statement timeout or context.
+func IsQueryCanceled(err error) bool { return Classify(err) == QueryCanceled }
+
+// IsConnectionFailure reports whether the connection to database is broken.
+func IsConnectionFailure(err error) bool { return Classify(err) == ConnectionFailure }
+
+// IsRetryable reports whether the transaction may be safely retried:
+// it is serialization failure, deadlock or MySQL lock wait timeout.
+func IsRetryable(err error) bool {
+	switch Classify(err) { //nolint:exhaustive // other classes are not retryable.
+	case SerializationFailure, Deadlock:
+		return true
+	case LockTimeout:
+		// MySQL rolls back the statement only, postgres lock_not_available
+		// may be caused by NOWAIT and is not retried.
+		code, ok := MySQLCode(err)
+
+		return ok && code == _myLockWaitTimeout
+	default:
+		return false
+	}
+}
+
+// IsNetworkError reports whether err is a driver bad connection error
+// or an error of network connection. Canceled and timed out contexts of
+// the caller are not network errors, though context.DeadlineExceeded
+// implements net.Error.
+func IsNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.ETIMEDOUT) ||
+		errors.As(err, &netErr)
+}
@@ -0,0 +1,319 @@
+package dberrors
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: Unknown,
+		},
+		{
+			name: "other error",
+			err:  errors.New("serialization failure"),
+			want: Unknown,
+		},
+		{
+			name: "pq serialization failure",
+			err:  &pq.Error{Code: "40001"},
+			want: SerializationFailure,
+		},
+		{
+			name: "pq wrapped serialization failure",
+			err:  fmt.Errorf("wrapped: %w", &pq.Error{Code: "40001"}),
+			want: SerializationFailure,
+		},
+		{
+			name: "pq deadlock",
+			err:  &pq.Error{Code: "40P01"},
+			want: Deadlock,
+		},
+		{
+			name: "pq unique violation",
+			err:  &pq.Error{Code: "23505"},
+			want: UniqueViolation,
+		},
+		{
+			name: "pq connection exception",
+			err:  &pq.Error{Code: "08006"},
+			want: ConnectionFailure,
+		},
+		{
+			name: "pgx serialization failure",
+			err:  &pgconn.PgError{Code: "40001"},
+			want: SerializationFailure,
+		},
+		{
+			name: "pgx foreign key violation",
+			err:  fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "23503"}),
+			want: ForeignKeyViolation,
+		},
+		{
+			name: "pgx not null violation",
+			err:  &pgconn.PgError{Code: "23502"},
+			want: NotNullViolation,
+		},
+		{
+			name: "pgx check violation",
+			err:  &pgconn.PgError{Code: "23514"},
+			want: CheckViolation,
+		},
+		{
+			name: "pgx lock not available",
+			err:  &pgconn.PgError{Code: "55P03"},
+			want: LockTimeout,
+		},
+		{
+			name: "pgx query canceled",
+			err:  &pgconn.PgError{Code: "57014"},
+			want: QueryCanceled,
+		},
+		{
+			name: "pgx admin shutdown",
+			err:  &pgconn.PgError{Code: "57P01"},
+			want: ConnectionFailure,
+		},
+		{
+			name: "pgx syntax error",
+			err:  &pgconn.PgError{Code: "42601"},
+			want: Unknown,
+		},
+		{
+			name: "sqlite busy",
+			err:  sqlite3.Error{Code: sqlite3.ErrBusy},
+			want: LockTimeout,
+		},
+		{
+			name: "sqlite locked",
+			err:  &sqlite3.Error{Code: sqlite3.ErrLocked},
+			want: LockTimeout,
+		},
+		{
+			name: "sqlite busy snapshot",
+			err:  sqlite3.Error{Code: sqlite3.ErrBusy, ExtendedCode: sqlite3.ErrBusySnapshot},
+			want: SerializationFailure,
+		},
+		{
+			name: "sqlite unique",
+			err:  fmt.Errorf("wrapped: %w", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}),
+			want: UniqueViolation,
+		},
+		{
+			name: "sqlite primary key",
+			err:  sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintPrimaryKey},
+			want: UniqueViolation,
+		},
+		{
+			name: "sqlite foreign key",
+			err:  sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey},
+			want: ForeignKeyViolation,
+		},
+		{
+			name: "sqlite not null",
+			err:  sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull},
+			want: NotNullViolation,
+		},
+		{
+			name: "sqlite check",
+			err:  sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintCheck},
+			want: CheckViolation,
+		},
+		{
+			name: "sqlite io error",
+			err:  sqlite3.Error{Code: sqlite3.ErrIoErr},
+			want: ConnectionFailure,
+		},
+		{
+			name: "mysql deadlock",
+			err:  fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: 1213}),
+			want: Deadlock,
+		},
+		{
+			name: "mysql lock wait timeout",
+			err:  &mysql.MySQLError{Number: 1205},
+			want: LockTimeout,
+		},
+		{
+			name: "mysql duplicate entry",
+			err:  &mysql.MySQLError{Number: 1062},
+			want: UniqueViolation,
+		},
+		{
+			name: "mysql foreign key",
+			err:  &mysql.MySQLError{Number: 1452},
+			want: ForeignKeyViolation,
+		},
+		{
+			name: "mariadb statement timeout",
+			err:  &mysql.MySQLError{Number: 1969},
+			want: QueryCanceled,
+		},
+		{
+			name: "mysql invalid connection",
+			err:  mysql.ErrInvalidConn,
+			want: ConnectionFailure,
+		},
+		{
+			name: "bad connection",
+			err:  fmt.Errorf("wrapped: %w", driver.ErrBadConn),
+			want: ConnectionFailure,
+		},
+		{
+			name: "connection init",
+			err:  fmt.Errorf("%w: %w", ErrConnectionInit, &pq.Error{Code: "42601"}),
+			want: ConnectionFailure,
+		},
+		{
+			name: "net op error",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			want: ConnectionFailure,
+		},
+		{
+			name: "context canceled",
+			err:  fmt.Errorf("wrapped: %w", context.Canceled),
+			want: QueryCanceled,
+		},
+		{
+			name: "context deadline exceeded",
+			err:  context.DeadlineExceeded,
+			want: QueryCanceled,
+		},
+		{
+			name: "wrapped context deadline exceeded",
+			err:  fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+			want: QueryCanceled,
+		},
+		{
+			name: "dial timeout of context",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: context.DeadlineExceeded},
+			want: QueryCanceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.err))
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "pq serialization failure",
+			err:  &pq.Error{Code: "40001"},
+			want: true,
+		},
+		{
+			name: "pgx serialization failure",
+			err:  &pgconn.PgError{Code: "40001"},
+			want: true,
+		},
+		{
+			name: "pgx deadlock",
+			err:  &pgconn.PgError{Code: "40P01"},
+			want: true,
+		},
+		{
+			name: "mysql deadlock",
+			err:  &mysql.MySQLError{Number: 1213},
+			want: true,
+		},
+		{
+			name: "mysql lock wait timeout",
+			err:  &mysql.MySQLError{Number: 1205},
+			want: true,
+		},
+		{
+			name: "pgx lock not available",
+			err:  &pgconn.PgError{Code: "55P03"},
+			want: false,
+		},
+		{
+			name: "unique violation",
+			err:  &pgconn.PgError{Code: "23505"},
+			want: false,
+		},
+		{
+			name: "nil",
+			err:  nil,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "bad connection",
+			err:  fmt.Errorf("wrapped: %w", driver.ErrBadConn),
+			want: true,
+		},
+		{
+			name: "net op error",
+			err:  &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+			want: true,
+		},
+		{
+			name: "context canceled",
+			err:  context.Canceled,
+			want: false,
+		},
+		{
+			name: "context deadline exceeded",
+			err:  context.DeadlineExceeded,
+			want: false,
+		},
+		{
+			name: "wrapped context canceled",
+			err:  fmt.Errorf("wrapped: %w", context.Canceled),
+			want: false,
+		},
+		{
+			name: "wrapped context deadline exceeded",
+			err:  fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+			want: false,
+		},
+		{
+			name: "net op error with context deadline exceeded",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: context.DeadlineExceeded},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsNetworkError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package dberrors
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL and MariaDB server error codes.
+// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	_myBadNull          = 1048
+	_myServerShutdown   = 1053
+	_myDupEntry         = 1062
+	_myLockWaitTimeout  = 1205
+	_myDeadlock         = 1213
+	_myQueryInterrupted = 1317
+	_myRowIsReferenced  = 1451
+	_myNoReferencedRow  = 1452
+	_myQueryTimeout     = 3024
+	_myCheckViolated    = 3819
+
+	_mariaStatementTimeout = 1969
+	_mariaConstraintFailed = 4025
+)
+
+// ClassifyMySQL returns class of go-sql-driver/mysql error.
+// It is also used for MariaDB which returns MySQL compatible codes.
+func ClassifyMySQL(err error) Class {
+	if errors.Is(err, mysql.ErrInvalidConn) {
+		return ConnectionFailure
+	}
+
+	code, ok := MySQLCode(err)
+	if !ok {
+		return Unknown
+	}
+
+	switch code {
+	case _myDeadlock:
+		return Deadlock
+	case _myLockWaitTimeout:
+		return LockTimeout
+	case _myDupEntry:
+		return UniqueViolation
+	case _myRowIsReferenced, _myNoReferencedRow:
+		return ForeignKeyViolation
+	case _myBadNull:
+		return NotNullViolation
+	case _myCheckViolated, _mariaConstraintFailed:
+		return CheckViolation
+	case _myQueryInterrupted, _myQueryTimeout, _mariaStatementTimeout:
+		return QueryCanceled
+	case _myServerShutdown:
+		return ConnectionFailure
+	default:
+		return Unknown
+	}
+}
+
+// MySQLCode returns server error number of go-sql-driver/mysql error.
+func MySQLCode(err error) (uint16, bool) {
+	var myErr *mysql.MySQLError
+
+	if errors.As(err, &myErr) {
+		return myErr.Number, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package dberrors
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// PostgreSQL error codes.
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	_pgSerializationFailure = "40001"
+	_pgDeadlockDetected     = "40P01"
+	_pgUniqueViolation      = "23505"
+	_pgForeignKeyViolation  = "23503"
+	_pgNotNullViolation     = "23502"
+	_pgCheckViolation       = "23514"
+	_pgLockNotAvailable     = "55P03"
+	_pgQueryCanceled        = "57014"
+	_pgAdminShutdown        = "57P01" // also returned by draining cockroach node.
+	_pgCrashShutdown        = "57P02"
+	_pgCannotConnectNow     = "57P03"
+
+	_pgConnectionExceptionClass = "08"
+)
+
+// ClassifyPostgres returns class of lib/pq or pgx error.
+// It is also used for CockroachDB which returns PostgreSQL compatible codes.
+func ClassifyPostgres(err error) Class {
+	code, ok := PostgresCode(err)
+	if !ok {
+		return Unknown
+	}
+
+	switch code {
+	case _pgSerializationFailure:
+		return SerializationFailure
+	case _pgDeadlockDetected:
+		return Deadlock
+	case _pgUniqueViolation:
+		return UniqueViolation
+	case _pgForeignKeyViolation:
+		return ForeignKeyViolation
+	case _pgNotNullViolation:
+		return NotNullViolation
+	case _pgCheckViolation:
+		return CheckViolation
+	case _pgLockNotAvailable:
+		return LockTimeout
+	case _pgQueryCanceled:
+		return QueryCanceled
+	case _pgAdminShutdown, _pgCrashShutdown, _pgCannotConnectNow:
+		return ConnectionFailure
+	}
+
+	if len(code) == 5 && code[:2] == _pgConnectionExceptionClass {
+		return ConnectionFailure
+	}
+
+	return Unknown
+}
+
+// PostgresCode returns SQLSTATE code of lib/pq or pgx error.
+func PostgresCode(err error) (string, bool) {
+	var (
+		pqErr *pq.Error
+		pgErr *pgconn.PgError
+	)
+
+	switch {
+	case errors.As(err, &pqErr):
+		return string(pqErr.Code), true
+	case errors.As(err, &pgErr):
+		return pgErr.Code, true
+	default:
+		return "", false
+	}
+}
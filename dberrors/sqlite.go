@@ -0,0 +1,91 @@
+package dberrors
+
+import (
+	"errors"
+	"reflect"
+)
+
+// SQLite result codes.
+// https://www.sqlite.org/rescode.html
+const (
+	_sqliteBusy       = 5
+	_sqliteLocked     = 6
+	_sqliteInterrupt  = 9
+	_sqliteIOErr      = 10
+	_sqliteCantOpen   = 14
+	_sqliteConstraint = 19
+
+	_sqliteBusySnapshot         = _sqliteBusy | 2<<8
+	_sqliteConstraintCheck      = _sqliteConstraint | 1<<8
+	_sqliteConstraintForeignKey = _sqliteConstraint | 3<<8
+	_sqliteConstraintNotNull    = _sqliteConstraint | 5<<8
+	_sqliteConstraintPrimaryKey = _sqliteConstraint | 6<<8
+	_sqliteConstraintUnique     = _sqliteConstraint | 8<<8
+)
+
+// ClassifySQLite returns class of go-sqlite3 error.
+func ClassifySQLite(err error) Class {
+	code, extended, ok := SQLiteCode(err)
+	if !ok {
+		return Unknown
+	}
+
+	switch extended {
+	case _sqliteBusySnapshot:
+		return SerializationFailure
+	case _sqliteConstraintUnique, _sqliteConstraintPrimaryKey:
+		return UniqueViolation
+	case _sqliteConstraintForeignKey:
+		return ForeignKeyViolation
+	case _sqliteConstraintNotNull:
+		return NotNullViolation
+	case _sqliteConstraintCheck:
+		return CheckViolation
+	}
+
+	switch code {
+	case _sqliteBusy, _sqliteLocked:
+		return LockTimeout
+	case _sqliteInterrupt:
+		return QueryCanceled
+	case _sqliteIOErr, _sqliteCantOpen:
+		return ConnectionFailure
+	default:
+		return Unknown
+	}
+}
+
+// SQLiteCode returns primary and extended result codes of go-sqlite3 error.
+// Error type is matched by reflection so as not to require cgo.
+func SQLiteCode(err error) (code, extended int64, ok bool) {
+	val, ok := findStruct(err, func(typ reflect.Type) bool {
+		return typ.PkgPath() == "github.com/mattn/go-sqlite3" && typ.Name() == "Error"
+	})
+	if !ok {
+		return 0, 0, false
+	}
+
+	if field := val.FieldByName("Code"); field.IsValid() && field.CanInt() {
+		code = field.Int()
+	}
+
+	if field := val.FieldByName("ExtendedCode"); field.IsValid() && field.CanInt() {
+		extended = field.Int()
+	}
+
+	return code, extended, true
+}
+
+// findStruct walks the err chain and returns the first error
+// which underlying struct type matches.
+func findStruct(err error, match func(typ reflect.Type) bool) (reflect.Value, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		val := reflect.Indirect(reflect.ValueOf(err))
+
+		if val.Kind() == reflect.Struct && match(val.Type()) {
+			return val, true
+		}
+	}
+
+	return reflect.Value{}, false
+}
@@ -1,4 +1,4 @@
 package database
 
 //nolint:lll // generate.
-//go:generate mockgen --build_flags=--mod=mod -destination mocks/metrics.go -package mocks github.com/loghole/database/hooks MetricCollector
+//go:generate mockgen --build_flags=--mod=mod -destination mocks/metrics.go -package mocks github.com/loghole/database/hooks MetricCollector,ExtendedMetricCollector
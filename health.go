@@ -0,0 +1,276 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/loghole/database/internal/dbsqlx"
+)
+
+// HealthState is a state of database connection reported by health checker.
+type HealthState string
+
+const (
+	HealthUnknown  HealthState = "unknown"
+	HealthHealthy  HealthState = "healthy"
+	HealthDegraded HealthState = "degraded"
+	HealthDown     HealthState = "down"
+)
+
+const (
+	DefaultHealthCheckInterval         = time.Second * 5
+	DefaultHealthCheckTimeout          = time.Second
+	DefaultHealthCheckFailureThreshold = 3
+)
+
+// HealthStatus is a result of the last health check.
+type HealthStatus struct {
+	State     HealthState
+	LastError error
+	Latency   time.Duration
+	CheckedAt time.Time
+
+	// ConsecutiveFailures is the number of failed checks in a row.
+	ConsecutiveFailures int
+
+	// Replicas contains statuses of read replicas.
+	Replicas []HealthStatus
+}
+
+// Ready reports whether database can serve queries.
+func (s HealthStatus) Ready() bool {
+	return s.State == HealthHealthy || s.State == HealthDegraded
+}
+
+// HealthCheckPolicy defines background health checks of database connection.
+type HealthCheckPolicy struct {
+	// Interval between checks.
+	//
+	// This field is required and must be greater than zero.
+	Interval time.Duration
+
+	// Timeout of a single check.
+	//
+	// This field is required and must be greater than zero.
+	Timeout time.Duration
+
+	// DegradedLatency is the check latency from which state is degraded.
+	// Zero value disables latency based degradation.
+	DegradedLatency time.Duration
+
+	// FailureThreshold is the number of failed checks in a row after which
+	// state is down. Fewer failures make state degraded.
+	//
+	// This field is required and must be greater than zero.
+	FailureThreshold int
+
+	// Reconnect enables reconnect when state is down.
+	Reconnect bool
+}
+
+type healthChecker struct {
+	policy    HealthCheckPolicy
+	ping      func(ctx context.Context) error
+	reconnect func() error
+
+	mu        sync.RWMutex
+	status    HealthStatus
+	startedAt time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func newHealthChecker(policy HealthCheckPolicy, ping func(ctx context.Context) error, reconnect func() error) *healthChecker {
+	return &healthChecker{
+		policy:    policy,
+		ping:      ping,
+		reconnect: reconnect,
+		status:    HealthStatus{State: HealthUnknown},
+		startedAt: time.Now(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+func (h *healthChecker) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		h.check()
+
+		select {
+		case <-ticker.C:
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+func (h *healthChecker) close() {
+	h.stopOnce.Do(func() { close(h.stop) })
+	<-h.done
+}
+
+func (h *healthChecker) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), h.policy.Timeout)
+	defer cancel()
+
+	startedAt := time.Now()
+
+	err := h.ping(ctx)
+
+	status := h.update(startedAt, time.Since(startedAt), err)
+
+	if status.State == HealthDown && h.policy.Reconnect {
+		_ = h.reconnect()
+	}
+}
+
+func (h *healthChecker) update(checkedAt time.Time, latency time.Duration, err error) HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.status.CheckedAt = checkedAt
+	h.status.Latency = latency
+	h.status.LastError = err
+
+	switch {
+	case err != nil:
+		h.status.ConsecutiveFailures++
+
+		if h.status.ConsecutiveFailures >= h.policy.FailureThreshold {
+			h.status.State = HealthDown
+		} else {
+			h.status.State = HealthDegraded
+		}
+	case h.policy.DegradedLatency > 0 && latency >= h.policy.DegradedLatency:
+		h.status.ConsecutiveFailures = 0
+		h.status.State = HealthDegraded
+	default:
+		h.status.ConsecutiveFailures = 0
+		h.status.State = HealthHealthy
+	}
+
+	return h.status
+}
+
+func (h *healthChecker) get() HealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.status
+}
+
+// stuck reports whether no check was finished for two intervals and timeout,
+// e.g. ping or reconnect doesn't return.
+func (h *healthChecker) stuck(now time.Time) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	last := h.startedAt
+
+	if h.status.CheckedAt.After(last) {
+		last = h.status.CheckedAt
+	}
+
+	return now.Sub(last) > 2*h.policy.Interval+h.policy.Timeout
+}
+
+// Status returns result of the last background health check.
+// State is unknown if health checks are not enabled with WithHealthCheck.
+func (db *DB) Status() HealthStatus {
+	status := HealthStatus{State: HealthUnknown}
+
+	if db.health != nil {
+		status = db.health.get()
+	}
+
+	if db.replicas != nil {
+		status.Replicas = make([]HealthStatus, 0, len(db.replicas.replicas))
+
+		for _, r := range db.replicas.replicas {
+			status.Replicas = append(status.Replicas, r.db.Status())
+		}
+	}
+
+	return status
+}
+
+// ReadinessHandler returns http handler for readiness probes. It responds
+// with 200 when database is healthy or degraded and with 503 otherwise.
+// Unknown state is ready if health checks are not enabled with WithHealthCheck.
+// The body contains JSON encoded status.
+func (db *DB) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := db.Status()
+
+		writeHealth(w, status.Ready() || (status.State == HealthUnknown && db.health == nil), status)
+	})
+}
+
+// LivenessHandler returns http handler for liveness probes. Unavailable
+// database is not a reason to restart the service, so it responds with 200
+// unless health checker is stuck and with 503 otherwise. The body contains
+// JSON encoded status.
+func (db *DB) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, db.health == nil || !db.health.stuck(time.Now()), db.Status())
+	})
+}
+
+func writeHealth(w http.ResponseWriter, ok bool, status HealthStatus) {
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(newHealthResponse(status))
+}
+
+// healthResponse doesn't contain LastError, errors of the driver may
+// disclose addresses, users and queries to the probe clients.
+type healthResponse struct {
+	State               HealthState      `json:"state"`
+	ConsecutiveFailures int              `json:"consecutive_failures"`
+	Latency             string           `json:"latency"`
+	CheckedAt           time.Time        `json:"checked_at"`
+	Replicas            []healthResponse `json:"replicas,omitempty"`
+}
+
+func newHealthResponse(status HealthStatus) healthResponse {
+	resp := healthResponse{
+		State:               status.State,
+		ConsecutiveFailures: status.ConsecutiveFailures,
+		Latency:             status.Latency.String(),
+		CheckedAt:           status.CheckedAt,
+	}
+
+	for _, replica := range status.Replicas {
+		resp.Replicas = append(resp.Replicas, newHealthResponse(replica))
+	}
+
+	return resp
+}
+
+func (db *DB) startHealthCheck() {
+	if db.options.healthCheck == nil {
+		return
+	}
+
+	db.health = newHealthChecker(*db.options.healthCheck, func(ctx context.Context) error {
+		return dbsqlx.Ping(ctx, db.SQLx())
+	}, db.reconnect)
+
+	go db.health.run()
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthChecker_update(t *testing.T) {
+	errPing := errors.New("ping")
+
+	type check struct {
+		latency time.Duration
+		err     error
+	}
+	tests := []struct {
+		name      string
+		checks    []check
+		wantState HealthState
+	}{
+		{
+			name:      "healthy",
+			checks:    []check{{latency: time.Millisecond}},
+			wantState: HealthHealthy,
+		},
+		{
+			name:      "slow",
+			checks:    []check{{latency: time.Second}},
+			wantState: HealthDegraded,
+		},
+		{
+			name:      "failed once",
+			checks:    []check{{err: errPing}},
+			wantState: HealthDegraded,
+		},
+		{
+			name:      "failed threshold",
+			checks:    []check{{err: errPing}, {err: errPing}},
+			wantState: HealthDown,
+		},
+		{
+			name:      "recovered",
+			checks:    []check{{err: errPing}, {err: errPing}, {latency: time.Millisecond}},
+			wantState: HealthHealthy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHealthChecker(HealthCheckPolicy{
+				Interval:         time.Second,
+				Timeout:          time.Second,
+				DegradedLatency:  time.Millisecond * 100,
+				FailureThreshold: 2,
+			}, nil, nil)
+
+			for _, c := range tt.checks {
+				h.update(time.Now(), c.latency, c.err)
+			}
+
+			assert.Equal(t, tt.wantState, h.get().State)
+		})
+	}
+}
+
+func TestHealthChecker_reconnect(t *testing.T) {
+	var reconnects int64
+
+	h := newHealthChecker(HealthCheckPolicy{
+		Interval:         time.Second,
+		Timeout:          time.Second,
+		FailureThreshold: 1,
+		Reconnect:        true,
+	}, func(ctx context.Context) error {
+		return errors.New("ping")
+	}, func() error {
+		atomic.AddInt64(&reconnects, 1)
+
+		return nil
+	})
+
+	h.check()
+
+	assert.Equal(t, HealthDown, h.get().State)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&reconnects))
+}
+
+func TestDB_ReadinessHandler(t *testing.T) {
+	policy := HealthCheckPolicy{Interval: time.Hour, Timeout: time.Second, FailureThreshold: 1}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		status    *HealthStatus
+		wantCode  int
+		wantState HealthState
+	}{
+		{
+			name:      "disabled",
+			wantCode:  http.StatusOK,
+			wantState: HealthUnknown,
+		},
+		{
+			name:      "healthy",
+			opts:      []Option{WithHealthCheck(policy)},
+			wantCode:  http.StatusOK,
+			wantState: HealthHealthy,
+		},
+		{
+			name:      "unknown",
+			opts:      []Option{WithHealthCheck(policy)},
+			status:    &HealthStatus{State: HealthUnknown},
+			wantCode:  http.StatusServiceUnavailable,
+			wantState: HealthUnknown,
+		},
+		{
+			name:      "down",
+			opts:      []Option{WithHealthCheck(policy)},
+			status:    &HealthStatus{State: HealthDown, LastError: errors.New("dial tcp 10.0.0.1:5432")},
+			wantCode:  http.StatusServiceUnavailable,
+			wantState: HealthDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := memorySQLLite(t, tt.opts...)
+			defer db.Close()
+
+			if db.health != nil {
+				require.Eventually(t, func() bool {
+					return db.Status().State != HealthUnknown
+				}, time.Second, time.Millisecond*10)
+			}
+
+			if tt.status != nil {
+				db.health.mu.Lock()
+				db.health.status = *tt.status
+				db.health.mu.Unlock()
+			}
+
+			rec := httptest.NewRecorder()
+
+			db.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "10.0.0.1", "must not disclose error")
+
+			var resp healthResponse
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+			assert.Equal(t, tt.wantState, resp.State)
+		})
+	}
+}
+
+func TestDB_LivenessHandler(t *testing.T) {
+	policy := HealthCheckPolicy{Interval: time.Hour, Timeout: time.Second, FailureThreshold: 1}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		stuck    bool
+		wantCode int
+	}{
+		{
+			name:     "disabled",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "down",
+			opts:     []Option{WithHealthCheck(policy)},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "stuck",
+			opts:     []Option{WithHealthCheck(policy)},
+			stuck:    true,
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := memorySQLLite(t, tt.opts...)
+			defer db.Close()
+
+			if db.health != nil {
+				require.Eventually(t, func() bool {
+					return db.Status().State != HealthUnknown
+				}, time.Second, time.Millisecond*10)
+
+				db.health.mu.Lock()
+				db.health.status.State = HealthDown
+
+				if tt.stuck {
+					db.health.startedAt = time.Now().Add(-3 * time.Hour)
+					db.health.status.CheckedAt = db.health.startedAt
+				}
+
+				db.health.mu.Unlock()
+			}
+
+			rec := httptest.NewRecorder()
+
+			db.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/live", nil))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package hooks
+
+import "github.com/loghole/dbhook"
+
+// Callers of pgx operations which don't pass through database/sql.
+const (
+	CallerBatch dbhook.CallerType = "batch"
+	CallerCopy  dbhook.CallerType = "copy"
+)
@@ -1,8 +1,20 @@
 package hooks
 
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
 // Config is internal hook config with specified information.
+//
+// Config never contains password: User is a user name only and
+// DataSourceName is redacted.
 type Config struct {
 	ReconnectFn func() error
+	// ReconnectContextFn is called instead of ReconnectFn if it is set,
+	// ctx is the context of the query failed with connection error.
+	ReconnectContextFn func(ctx context.Context) error
 
 	Addr           string
 	User           string
@@ -14,4 +26,108 @@ type Config struct {
 	DataSourceName string
 	DriverName     string
 	Instance       string
+
+	// Node is a database node connections are established to. It is set
+	// by DB and updated when connections move to other node or server.
+	Node *Node
+}
+
+// CurrentAddr returns address of the node in use. It is Addr if Node is not set.
+func (c *Config) CurrentAddr() string {
+	if addr := c.Node.Addr(); addr != "" {
+		return addr
+	}
+
+	return c.Addr
+}
+
+// CurrentInstance returns instance of the server in use. It is Instance
+// if Node is not set.
+func (c *Config) CurrentInstance() string {
+	if instance := c.Node.Instance(); instance != "" {
+		return instance
+	}
+
+	return c.Instance
+}
+
+// Node holds address and instance of the node in use,
+// it is safe for concurrent use.
+type Node struct {
+	mu          sync.RWMutex
+	addr        string
+	instance    string
+	instanceErr error
+}
+
+// Addr returns address of the node. It returns empty string for nil Node.
+func (n *Node) Addr() string {
+	if n == nil {
+		return ""
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.addr
+}
+
+// SetAddr sets address of the node.
+func (n *Node) SetAddr(addr string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.addr = addr
+}
+
+// Instance returns server instance of the node, e.g. host name or node id.
+// It returns empty string for nil Node.
+func (n *Node) Instance() string {
+	if n == nil {
+		return ""
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.instance
+}
+
+// SetInstance sets server instance of the node.
+func (n *Node) SetInstance(instance string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.instance = instance
+}
+
+// InstanceErr returns error of the last instance discovery or nil.
+// It returns nil for nil Node.
+func (n *Node) InstanceErr() error {
+	if n == nil {
+		return nil
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.instanceErr
+}
+
+// SetInstanceErr sets error of instance discovery, nil means it succeeded.
+func (n *Node) SetInstanceErr(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.instanceErr = err
+}
+
+// String returns database type and redacted data source name.
+func (c Config) String() string { //nolint:gocritic // value receiver is required to print values.
+	return fmt.Sprintf("%s(%s)", c.Type, c.DataSourceName)
+}
+
+// GoString returns the same as String, reconnect funcs are omitted.
+func (c Config) GoString() string { //nolint:gocritic // value receiver is required to print values.
+	return c.String()
 }
@@ -0,0 +1,19 @@
+//go:build go1.21
+
+package hooks
+
+import (
+	"log/slog"
+)
+
+// LogValue implements slog.LogValuer.
+func (c Config) LogValue() slog.Value { //nolint:gocritic // value receiver is required to log values.
+	return slog.GroupValue(
+		slog.String("type", c.Type),
+		slog.String("addr", c.CurrentAddr()),
+		slog.String("user", c.User),
+		slog.String("database", c.Database),
+		slog.String("instance", c.CurrentInstance()),
+		slog.String("dsn", c.DataSourceName),
+	)
+}
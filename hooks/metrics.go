@@ -8,7 +8,7 @@ import (
 
 	"github.com/loghole/dbhook"
 
-	"github.com/loghole/database/internal/helpers"
+	"github.com/loghole/database/dberrors"
 	"github.com/loghole/database/internal/query"
 )
 
@@ -17,6 +17,62 @@ type MetricCollector interface {
 	QueryDurationObserve(dbType, dbAddr, dbName, operation, table string, isError bool, since time.Duration)
 }
 
+// RetryCollector is an optional interface of MetricCollector
+// which collects retries of DB operations.
+type RetryCollector interface {
+	RetryInc(dbType, dbAddr, dbName, operation string)
+}
+
+// ReconnectCollector is an optional interface of MetricCollector
+// which collects attempts to replace connection pool.
+type ReconnectCollector interface {
+	ReconnectInc(dbType, dbAddr, dbName string, isError bool)
+}
+
+// TransactionCollector is an optional interface of MetricCollector which
+// collects duration of transactions of RunTxx from begin to commit or rollback.
+// Transactions of BeginTxx are not observed.
+type TransactionCollector interface {
+	TransactionDurationObserve(dbType, dbAddr, dbName string, isCommit bool, since time.Duration)
+}
+
+// RowsCollector is an optional interface of MetricCollector which collects
+// the number of rows returned by SelectContext and GetContext and affected
+// by ExecContext and NamedExecContext.
+type RowsCollector interface {
+	RowsReturnedObserve(dbType, dbAddr, dbName, operation, table string, rows int64)
+	RowsAffectedObserve(dbType, dbAddr, dbName, operation, table string, rows int64)
+}
+
+// ExtendedMetricCollector is MetricCollector with all optional interfaces.
+type ExtendedMetricCollector interface {
+	MetricCollector
+	RetryCollector
+	ReconnectCollector
+	TransactionCollector
+	RowsCollector
+}
+
+// PoolStatsCollector collects statistics of connection pool. Counters of
+// stats are cumulative, they don't reset on reconnect to the same address
+// and start from zero for a new address.
+type PoolStatsCollector interface {
+	PoolStatsObserve(dbType, dbAddr, dbName string, stats sql.DBStats)
+}
+
+// PoolStatsDeleter is an optional interface of PoolStatsCollector which
+// drops statistics of the address that is no longer in use, e.g. after
+// reconnect to other node or Close.
+type PoolStatsDeleter interface {
+	PoolStatsDelete(dbType, dbAddr, dbName string)
+}
+
+// PendingRequestsCollector is an optional interface of PoolStatsCollector
+// which collects the number of requests waiting for a connection.
+type PendingRequestsCollector interface {
+	PendingRequestsObserve(dbType, dbAddr, dbName string, pending int)
+}
+
 type MetricsHook struct {
 	startedAtContextKey struct{}
 
@@ -44,8 +100,8 @@ func (h *MetricsHook) After(ctx context.Context, input *dbhook.HookInput) (conte
 }
 
 func (h *MetricsHook) Error(ctx context.Context, input *dbhook.HookInput) (context.Context, error) {
-	if helpers.IsSerialisationFailureErr(input.Error) {
-		h.collector.SerializationFailureInc(h.config.Type, h.config.Addr, h.config.Database)
+	if dberrors.IsSerializationFailure(input.Error) {
+		h.collector.SerializationFailureInc(h.config.Type, h.config.CurrentAddr(), h.config.Database)
 	}
 
 	return h.finish(ctx, input)
@@ -57,7 +113,7 @@ func (h *MetricsHook) finish(ctx context.Context, input *dbhook.HookInput) (cont
 
 		h.collector.QueryDurationObserve(
 			h.config.Type,
-			h.config.Addr,
+			h.config.CurrentAddr(),
 			h.config.Database,
 			operation,
 			table,
@@ -72,13 +128,15 @@ func (h *MetricsHook) finish(ctx context.Context, input *dbhook.HookInput) (cont
 func (h *MetricsHook) isError(err error) bool {
 	return err != nil &&
 		!errors.Is(err, sql.ErrNoRows) &&
-		!helpers.IsSerialisationFailureErr(err)
+		!dberrors.IsSerializationFailure(err)
 }
 
 func (h *MetricsHook) parseOperation(input *dbhook.HookInput) (operation, table string) {
 	switch input.Caller { //nolint:exhaustive // not need other types.
 	case dbhook.CallerBegin, dbhook.CallerCommit, dbhook.CallerRollback:
 		return "tx." + string(input.Caller), ""
+	case CallerBatch:
+		return string(CallerBatch), ""
 	}
 
 	parsed := h.parser.Parse(input.Query)
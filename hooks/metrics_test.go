@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/lib/pq"
 	"github.com/loghole/dbhook"
 
@@ -135,6 +136,74 @@ func TestMetricsHook(t *testing.T) {
 				ctx, _ = hook.Error(ctx, input)
 			},
 		},
+		{
+			name: "batch",
+			args: args{
+				config: &Config{
+					Addr:     "127.0.0.1:5432",
+					User:     "test",
+					Database: "postgresdb",
+					Type:     "pgx",
+				},
+				makeCollector: func() MetricCollector {
+					collector := mocks.NewMockMetricCollector(ctrl)
+					collector.EXPECT().QueryDurationObserve(
+						"pgx",
+						"127.0.0.1:5432",
+						"postgresdb",
+						"batch",
+						"",
+						false,
+						gomock.Any(),
+					)
+
+					return collector
+				},
+			},
+			do: func(hook *MetricsHook) {
+				input := &dbhook.HookInput{
+					Query:  "INSERT INTO users (id) VALUES ($1);\nSELECT 1",
+					Caller: CallerBatch,
+				}
+
+				ctx, _ = hook.Before(ctx, input)
+				ctx, _ = hook.After(ctx, input)
+			},
+		},
+		{
+			name: "copy",
+			args: args{
+				config: &Config{
+					Addr:     "127.0.0.1:5432",
+					User:     "test",
+					Database: "postgresdb",
+					Type:     "pgx",
+				},
+				makeCollector: func() MetricCollector {
+					collector := mocks.NewMockMetricCollector(ctrl)
+					collector.EXPECT().QueryDurationObserve(
+						"pgx",
+						"127.0.0.1:5432",
+						"postgresdb",
+						"copy",
+						"users",
+						false,
+						gomock.Any(),
+					)
+
+					return collector
+				},
+			},
+			do: func(hook *MetricsHook) {
+				input := &dbhook.HookInput{
+					Query:  "COPY users (id, name) FROM STDIN",
+					Caller: CallerCopy,
+				}
+
+				ctx, _ = hook.Before(ctx, input)
+				ctx, _ = hook.After(ctx, input)
+			},
+		},
 		{
 			name: "serialization failure",
 			args: args{
@@ -177,6 +246,48 @@ func TestMetricsHook(t *testing.T) {
 				ctx, _ = hook.Error(ctx, input)
 			},
 		},
+		{
+			name: "pgx serialization failure",
+			args: args{
+				config: &Config{
+					Addr:     "127.0.0.1:5432",
+					User:     "test",
+					Database: "postgresdb",
+					Type:     "pgx",
+				},
+				makeCollector: func() MetricCollector {
+					collector := mocks.NewMockMetricCollector(ctrl)
+					collector.EXPECT().QueryDurationObserve(
+						"pgx",
+						"127.0.0.1:5432",
+						"postgresdb",
+						"update",
+						"users",
+						false,
+						gomock.Any(),
+					)
+					collector.EXPECT().SerializationFailureInc(
+						"pgx",
+						"127.0.0.1:5432",
+						"postgresdb",
+					)
+
+					return collector
+				},
+			},
+			do: func(hook *MetricsHook) {
+				input := &dbhook.HookInput{
+					Query:  "UPDATE users SET name = $1",
+					Caller: dbhook.CallerQuery,
+				}
+
+				ctx, _ = hook.Before(ctx, input)
+
+				input.Error = &pgconn.PgError{Code: "40001"}
+
+				ctx, _ = hook.Error(ctx, input)
+			},
+		},
 		{
 			name: "unknown query",
 			args: args{
@@ -4,26 +4,56 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/loghole/dbhook"
+
+	"github.com/loghole/database/dberrors"
 )
 
+// ErrorMatcher reports whether the error means that connection is broken
+// and connection pool must be recreated.
+type ErrorMatcher func(err error) bool
+
 type ReconnectHook struct {
-	config *Config
+	config   *Config
+	matchers []ErrorMatcher
 }
 
 var ErrCanRetry = errors.New("connection reconnect")
 
-func NewReconnectHook(config *Config) *ReconnectHook {
+type withoutReconnectContextKey struct{}
+
+// WithoutReconnect returns context in which ReconnectHook doesn't reconnect.
+// It marks queries of reconnect itself, e.g. session init of a new pool,
+// which would otherwise wait for the reconnect they are part of.
+func WithoutReconnect(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutReconnectContextKey{}, true)
+}
+
+// reconnectDisabled reports whether the query must not reconnect.
+// Reconnect funcs are nil until the DB is ready to reconnect.
+func (rh *ReconnectHook) reconnectDisabled(ctx context.Context) bool {
+	if rh.config.ReconnectFn == nil && rh.config.ReconnectContextFn == nil {
+		return true
+	}
+
+	disabled, _ := ctx.Value(withoutReconnectContextKey{}).(bool)
+
+	return disabled
+}
+
+// NewReconnectHook returns hook that recreates connection pool on connection errors.
+// Errors are matched by default matchers for config.Type and by custom matchers.
+func NewReconnectHook(config *Config, matchers ...ErrorMatcher) *ReconnectHook {
 	return &ReconnectHook{
-		config: config,
+		config:   config,
+		matchers: append(DefaultReconnectMatchers(config.Type), matchers...),
 	}
 }
 
 func (rh *ReconnectHook) Error(ctx context.Context, input *dbhook.HookInput) (context.Context, error) {
-	if input.Error != nil && isReconnectError(input.Error) {
-		if err := rh.config.ReconnectFn(); err != nil {
+	if input.Error != nil && !rh.reconnectDisabled(ctx) && rh.isReconnectError(input.Error) {
+		if err := rh.reconnect(ctx); err != nil {
 			return ctx, fmt.Errorf("reconnect error: %w", err)
 		}
 
@@ -33,12 +63,68 @@ func (rh *ReconnectHook) Error(ctx context.Context, input *dbhook.HookInput) (co
 	return ctx, input.Error
 }
 
-func isReconnectError(err error) bool {
-	msg := err.Error()
+func (rh *ReconnectHook) reconnect(ctx context.Context) error {
+	if rh.config.ReconnectContextFn != nil {
+		return rh.config.ReconnectContextFn(ctx)
+	}
+
+	return rh.config.ReconnectFn()
+}
+
+func (rh *ReconnectHook) isReconnectError(err error) bool {
+	for _, match := range rh.matchers {
+		if match(err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DefaultReconnectMatchers returns connection error matchers for database type.
+func DefaultReconnectMatchers(dbType string) []ErrorMatcher {
+	matchers := []ErrorMatcher{IsNetworkError}
+
+	switch dbType {
+	case "postgres", "pgx", "cockroach":
+		matchers = append(matchers, IsPostgresConnectionError)
+	case "mysql":
+		matchers = append(matchers, IsMySQLConnectionError)
+	case "sqlite3":
+		matchers = append(matchers, IsSQLiteConnectionError)
+	case "clickhouse":
+		matchers = append(matchers, IsClickHouseConnectionError)
+	}
+
+	return matchers
+}
+
+// IsNetworkError reports whether err is a driver bad connection error
+// or an error of network connection.
+func IsNetworkError(err error) bool {
+	return dberrors.IsNetworkError(err)
+}
+
+// IsPostgresConnectionError reports whether err is lib/pq or pgx error with
+// connection exception class or server shutdown code.
+func IsPostgresConnectionError(err error) bool {
+	return dberrors.ClassifyPostgres(err) == dberrors.ConnectionFailure
+}
+
+// IsMySQLConnectionError reports whether err is go-sql-driver/mysql invalid
+// connection error or server shutdown error.
+func IsMySQLConnectionError(err error) bool {
+	return dberrors.ClassifyMySQL(err) == dberrors.ConnectionFailure
+}
+
+// IsSQLiteConnectionError reports whether err is go-sqlite3 error with
+// disk I/O or can't open database code.
+func IsSQLiteConnectionError(err error) bool {
+	return dberrors.ClassifySQLite(err) == dberrors.ConnectionFailure
+}
 
-	return strings.Contains(msg, "broken pipe") ||
-		strings.Contains(msg, "bad connection") ||
-		strings.Contains(msg, "connection timed out") ||
-		strings.Contains(msg, "unexpected EOF") ||
-		strings.Contains(msg, "server is not accepting clients")
+// IsClickHouseConnectionError reports whether err is clickhouse-go exception
+// with socket timeout or network error code.
+func IsClickHouseConnectionError(err error) bool {
+	return dberrors.ClassifyClickHouse(err) == dberrors.ConnectionFailure
 }
@@ -0,0 +1,264 @@
+package hooks
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/loghole/dbhook"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconnectHook_isReconnectError(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	tests := []struct {
+		name     string
+		dbType   string
+		matchers []ErrorMatcher
+		err      error
+		want     bool
+	}{
+		{
+			name:   "bad connection",
+			dbType: "postgres",
+			err:    fmt.Errorf("wrapped: %w", driver.ErrBadConn),
+			want:   true,
+		},
+		{
+			name:   "unexpected EOF",
+			dbType: "pgx",
+			err:    fmt.Errorf("wrapped: %w", io.ErrUnexpectedEOF),
+			want:   true,
+		},
+		{
+			name:   "net op error",
+			dbType: "clickhouse",
+			err:    &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+			want:   true,
+		},
+		{
+			name:   "broken pipe",
+			dbType: "postgres",
+			err:    fmt.Errorf("write: %w", syscall.EPIPE),
+			want:   true,
+		},
+		{
+			name:   "lib/pq connection exception",
+			dbType: "postgres",
+			err:    &pq.Error{Code: "08006"},
+			want:   true,
+		},
+		{
+			name:   "lib/pq admin shutdown",
+			dbType: "postgres",
+			err:    &pq.Error{Code: "57P01", Message: "server is not accepting clients"},
+			want:   true,
+		},
+		{
+			name:   "lib/pq syntax error",
+			dbType: "postgres",
+			err:    &pq.Error{Code: "42601"},
+			want:   false,
+		},
+		{
+			name:   "pgx connection exception",
+			dbType: "pgx",
+			err:    fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "08003"}),
+			want:   true,
+		},
+		{
+			name:   "pgx unique violation",
+			dbType: "pgx",
+			err:    &pgconn.PgError{Code: "23505", Message: "broken pipe"},
+			want:   false,
+		},
+		{
+			name:   "sqlite io error",
+			dbType: "sqlite3",
+			err:    sqlite3.Error{Code: sqlite3.ErrIoErr},
+			want:   true,
+		},
+		{
+			name:   "sqlite pointer error",
+			dbType: "sqlite3",
+			err:    fmt.Errorf("wrapped: %w", &sqlite3.Error{Code: sqlite3.ErrCantOpen}),
+			want:   true,
+		},
+		{
+			name:   "sqlite constraint error",
+			dbType: "sqlite3",
+			err:    sqlite3.Error{Code: sqlite3.ErrConstraint},
+			want:   false,
+		},
+		{
+			name:   "mysql invalid connection",
+			dbType: "mysql",
+			err:    fmt.Errorf("wrapped: %w", mysql.ErrInvalidConn),
+			want:   true,
+		},
+		{
+			name:   "mysql deadlock",
+			dbType: "mysql",
+			err:    &mysql.MySQLError{Number: 1213},
+			want:   false,
+		},
+		{
+			name:   "clickhouse postgres error",
+			dbType: "clickhouse",
+			err:    &pq.Error{Code: "08006"},
+			want:   false,
+		},
+		{
+			name:   "user data in message",
+			dbType: "postgres",
+			err:    errors.New(`duplicate key value "unexpected EOF broken pipe"`),
+			want:   false,
+		},
+		{
+			name:     "custom matcher",
+			dbType:   "postgres",
+			matchers: []ErrorMatcher{func(err error) bool { return errors.Is(err, errCustom) }},
+			err:      errCustom,
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := NewReconnectHook(&Config{Type: tt.dbType}, tt.matchers...)
+
+			assert.Equal(t, tt.want, hook.isReconnectError(tt.err))
+		})
+	}
+}
+
+func TestDefaultReconnectMatchers(t *testing.T) {
+	tests := []struct {
+		dbType string
+		want   ErrorMatcher
+	}{
+		{dbType: "postgres", want: IsPostgresConnectionError},
+		{dbType: "pgx", want: IsPostgresConnectionError},
+		{dbType: "cockroach", want: IsPostgresConnectionError},
+		{dbType: "mysql", want: IsMySQLConnectionError},
+		{dbType: "sqlite3", want: IsSQLiteConnectionError},
+		{dbType: "clickhouse", want: IsClickHouseConnectionError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			matchers := DefaultReconnectMatchers(tt.dbType)
+
+			// Functions can't be compared, so their addresses are.
+			require.Len(t, matchers, 2)
+			assert.Equal(t, reflect.ValueOf(tt.want).Pointer(), reflect.ValueOf(matchers[1]).Pointer())
+		})
+	}
+}
+
+func TestIsClickHouseConnectionError(t *testing.T) {
+	// clickhouse-go exceptions are matched by package path of the driver,
+	// so only errors of other drivers can be checked without it.
+	assert.False(t, IsClickHouseConnectionError(errors.New("code: 210")))
+	assert.False(t, IsClickHouseConnectionError(&pgconn.PgError{Code: "08006"}))
+}
+
+func TestReconnectHook_Error(t *testing.T) {
+	errReconnect := errors.New("reconnect")
+
+	tests := []struct {
+		name        string
+		reconnectFn func() error
+		err         error
+		wantErr     error
+	}{
+		{
+			name:        "reconnected",
+			reconnectFn: func() error { return nil },
+			err:         driver.ErrBadConn,
+			wantErr:     ErrCanRetry,
+		},
+		{
+			name:        "reconnect failed",
+			reconnectFn: func() error { return errReconnect },
+			err:         driver.ErrBadConn,
+			wantErr:     errReconnect,
+		},
+		{
+			name:    "other error",
+			err:     io.EOF,
+			wantErr: io.EOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := NewReconnectHook(&Config{Type: "postgres", ReconnectFn: tt.reconnectFn})
+
+			_, err := hook.Error(context.Background(), &dbhook.HookInput{Error: tt.err})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReconnectHook_Error_withoutReconnect(t *testing.T) {
+	hook := NewReconnectHook(&Config{Type: "postgres", ReconnectFn: func() error {
+		t.Fatal("reconnect must not be called")
+
+		return nil
+	}})
+
+	_, err := hook.Error(WithoutReconnect(context.Background()), &dbhook.HookInput{Error: driver.ErrBadConn})
+
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.NotErrorIs(t, err, ErrCanRetry)
+}
+
+func TestReconnectHook_Error_reconnectContextFn(t *testing.T) {
+	type ctxKey struct{}
+
+	var got context.Context
+
+	hook := NewReconnectHook(&Config{
+		Type: "postgres",
+		ReconnectFn: func() error {
+			t.Fatal("ReconnectFn must not be called")
+
+			return nil
+		},
+		ReconnectContextFn: func(ctx context.Context) error {
+			got = ctx
+
+			return nil
+		},
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "query")
+
+	_, err := hook.Error(ctx, &dbhook.HookInput{Error: driver.ErrBadConn})
+
+	assert.ErrorIs(t, err, ErrCanRetry)
+	require.NotNil(t, got)
+	assert.Equal(t, "query", got.Value(ctxKey{}))
+}
+
+func TestReconnectHook_Error_nilReconnectFn(t *testing.T) {
+	hook := NewReconnectHook(&Config{Type: "postgres"})
+
+	assert.NotPanics(t, func() {
+		_, err := hook.Error(context.Background(), &dbhook.HookInput{Error: driver.ErrBadConn})
+
+		assert.ErrorIs(t, err, driver.ErrBadConn)
+		assert.NotErrorIs(t, err, ErrCanRetry)
+	})
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/lissteron/simplerr"
 	"github.com/loghole/dbhook"
+
+	"github.com/loghole/database/dberrors"
 )
 
 type SimplerrHook struct{}
@@ -28,6 +30,10 @@ func (h *SimplerrHook) Error(ctx context.Context, input *dbhook.HookInput) (cont
 		return ctx, simplerr.WrapWithCode(input.Error, Reconnected, "reconnected, try again")
 	}
 
+	if dberrors.IsConnectionFailure(input.Error) {
+		return ctx, simplerr.WrapWithCode(input.Error, BadConnection, "connection refused, try later")
+	}
+
 	msg := input.Error.Error()
 
 	if strings.HasSuffix(msg, "server is not accepting clients") {
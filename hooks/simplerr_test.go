@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/lib/pq"
 	"github.com/lissteron/simplerr"
 	"github.com/loghole/dbhook"
 	"github.com/stretchr/testify/assert"
@@ -108,6 +109,22 @@ func TestSimplerrHook_Error(t *testing.T) {
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				assert.Equal(t, simplerr.GetCode(err).Int(), int(BadConnection))
 
+				return true
+			},
+		},
+		{
+			name: "postgres connection exception",
+			args: args{
+				ctx: context.Background(),
+				input: &dbhook.HookInput{
+					Query:  "SELECT 1",
+					Error:  &pq.Error{Code: "08006"},
+					Caller: dbhook.CallerQuery,
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				assert.Equal(t, simplerr.GetCode(err).Int(), int(BadConnection))
+
 				return true
 			},
 		},
@@ -31,8 +31,8 @@ func (hook *TracingHook) Before(ctx context.Context, input *dbhook.HookInput) (c
 		semconv.DBSystemKey.String(hook.config.Type),
 		semconv.DBNameKey.String(hook.config.Database),
 		semconv.DBStatementKey.String(input.Query),
-		semconv.HostIDKey.String(hook.config.Instance),
-		semconv.HostNameKey.String(hook.config.Addr),
+		semconv.HostIDKey.String(hook.config.CurrentInstance()),
+		semconv.HostNameKey.String(hook.config.CurrentAddr()),
 	)
 
 	return ctx, nil
@@ -58,6 +58,41 @@ func TestTracingHook(t *testing.T) {
 			},
 			wantStatus: codes.Unset,
 		},
+		{
+			name: "node in use",
+			args: args{
+				config: func() *Config {
+					node := &Node{}
+					node.SetAddr("node-2:26257")
+
+					return &Config{
+						User:     "test",
+						Database: "postgresdb",
+						Type:     "cockroach",
+						Instance: "2",
+						Node:     node,
+					}
+				}(),
+			},
+			do: func(hook *TracingHook) {
+				input := &dbhook.HookInput{
+					Query:  "SELECT id FROM users",
+					Caller: dbhook.CallerQuery,
+				}
+
+				ctx, _ = hook.Before(ctx, input)
+				ctx, _ = hook.After(ctx, input)
+			},
+			wantAttrs: []attribute.KeyValue{
+				semconv.DBUserKey.String("test"),
+				semconv.DBSystemKey.String("cockroach"),
+				semconv.DBNameKey.String("postgresdb"),
+				semconv.DBStatementKey.String("SELECT id FROM users"),
+				semconv.HostIDKey.String("2"),
+				semconv.HostNameKey.String("node-2:26257"),
+			},
+			wantStatus: codes.Unset,
+		},
 		{
 			name: "failed query",
 			args: args{
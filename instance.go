@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/loghole/database/internal/dbsqlx"
+)
+
+const (
+	_unknownInstance = "-"
+	_instanceTimeout = time.Second * 5
+)
+
+// Instance returns instance of the server in use: node id for cockroach,
+// server address and system identifier for postgres and pgx, host name
+// for clickhouse, host name and port for mysql and file path for sqlite.
+// It is "-" until instance is discovered, in lazy mode and if discovery
+// failed, see InstanceErr.
+func (db *DB) Instance() string {
+	return db.hooksCfg.CurrentInstance()
+}
+
+// InstanceErr returns error of the last instance discovery or nil.
+func (db *DB) InstanceErr() error {
+	return db.hooksCfg.Node.InstanceErr()
+}
+
+// discoverInstance queries instance of the server and sets it to hooks
+// config. Instance is "-" if it can't be discovered, the error is recorded
+// to hooks config node. Queries bypass hooks, so they are not traced,
+// observed or reconnected.
+func (db *DB) discoverInstance(ctx context.Context, conn *sqlx.DB) {
+	ctx, cancel := context.WithTimeout(ctx, _instanceTimeout)
+	defer cancel()
+
+	instance, err := queryInstance(ctx, conn, db.baseCfg)
+	if err != nil {
+		err = fmt.Errorf("discover %s instance: %w", db.baseCfg.Type, err)
+	}
+
+	if err != nil || instance == "" {
+		instance = _unknownInstance
+	}
+
+	db.hooksCfg.Node.SetInstance(instance)
+	db.hooksCfg.Node.SetInstanceErr(err)
+}
+
+func queryInstance(ctx context.Context, conn *sqlx.DB, cfg *Config) (string, error) {
+	switch cfg.Type {
+	case CockroachDatabase:
+		return queryString(ctx, conn, `SHOW node_id`)
+	case PostgresDatabase, PGXDatabase:
+		return queryPostgresInstance(ctx, conn)
+	case ClickhouseDatabase:
+		return queryString(ctx, conn, `SELECT hostName()`)
+	case MySQLDatabase:
+		return queryString(ctx, conn, `SELECT CONCAT(@@hostname, ':', @@port)`)
+	case SQLiteDatabase:
+		path, err := queryString(ctx, conn, `SELECT file FROM pragma_database_list WHERE name = 'main'`)
+		if err != nil || path == "" {
+			return cfg.Database, nil //nolint:nilerr // in-memory database has no file.
+		}
+
+		return path, nil
+	default:
+		return "", nil
+	}
+}
+
+// queryPostgresInstance returns server address and system identifier.
+// System identifier is omitted if pg_control_system is not permitted.
+func queryPostgresInstance(ctx context.Context, conn *sqlx.DB) (string, error) {
+	var (
+		host sql.NullString
+		port sql.NullInt64
+	)
+
+	err := dbsqlx.QueryRow(ctx, conn, `SELECT host(inet_server_addr()), inet_server_port()`, &host, &port)
+	if err != nil {
+		return "", err //nolint:wrapcheck // need clean err.
+	}
+
+	instance := "local" // unix socket connection.
+
+	if host.Valid {
+		instance = net.JoinHostPort(host.String, strconv.FormatInt(port.Int64, 10))
+	}
+
+	if id, err := queryString(ctx, conn, `SELECT system_identifier::text FROM pg_control_system()`); err == nil {
+		instance += "/" + id
+	}
+
+	return instance, nil
+}
+
+func queryString(ctx context.Context, conn *sqlx.DB, query string) (string, error) {
+	var val sql.NullString
+
+	if err := dbsqlx.QueryRow(ctx, conn, query, &val); err != nil {
+		return "", err //nolint:wrapcheck // need clean err.
+	}
+
+	return val.String, nil
+}
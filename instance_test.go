@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Instance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	tests := []struct {
+		name string
+		cfg  *Config
+		opts []Option
+		want string
+	}{
+		{
+			name: "sqlite file",
+			cfg:  &Config{Database: path, Type: SQLiteDatabase},
+			want: path,
+		},
+		{
+			name: "sqlite memory",
+			cfg:  &Config{Database: ":memory:", Type: SQLiteDatabase},
+			want: ":memory:",
+		},
+		{
+			name: "lazy",
+			cfg:  &Config{Database: path, Type: SQLiteDatabase},
+			opts: []Option{WithLazyConnect()},
+			want: "-",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := New(tt.cfg, tt.opts...)
+			require.NoError(t, err)
+
+			defer db.Close()
+
+			assert.Equal(t, tt.want, db.Instance())
+			assert.NoError(t, db.InstanceErr())
+		})
+	}
+}
+
+func TestDB_Instance_reconnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := New(&Config{Database: path, Type: SQLiteDatabase}, WithLazyConnect())
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	assert.Equal(t, "-", db.Instance())
+
+	require.NoError(t, db.reconnect())
+
+	assert.Equal(t, path, db.Instance())
+}
+
+func TestDB_discoverInstance_failed(t *testing.T) {
+	db, err := New(&Config{Database: ":memory:", Type: SQLiteDatabase},
+		WithReconnectHook(func(err error) bool { return true }))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	// MySQL query fails on sqlite.
+	db.baseCfg = &Config{Database: ":memory:", Type: MySQLDatabase}
+
+	db.discoverInstance(context.Background(), db.SQLx())
+
+	assert.Equal(t, "-", db.Instance())
+	assert.ErrorContains(t, db.InstanceErr(), "discover mysql instance")
+	assert.True(t, db.reconnector.lastAt.IsZero(), "discovery must not reconnect")
+
+	db.baseCfg = &Config{Database: ":memory:", Type: SQLiteDatabase}
+
+	db.discoverInstance(context.Background(), db.SQLx())
+
+	assert.Equal(t, ":memory:", db.Instance())
+	assert.NoError(t, db.InstanceErr(), "must be reset by successful discovery")
+}
@@ -0,0 +1,228 @@
+package dbsqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/loghole/dbhook"
+
+	"github.com/loghole/database/dberrors"
+)
+
+// drivers caches drivers found by name.
+var drivers sync.Map // map[string]driver.Driver
+
+// OnConnectFunc initializes a new connection.
+type OnConnectFunc func(ctx context.Context, session *Session) error
+
+// Config is a connector config.
+type Config struct {
+	DriverName     string
+	DataSourceName string
+
+	// DataSourceFunc returns data source name for every new connection.
+	// If it is set, DataSourceName is ignored.
+	DataSourceFunc func(ctx context.Context) (string, error)
+
+	// OpenConnector opens connector for data source name. If it is not set,
+	// the connector of the registered driver is used.
+	OpenConnector func(dsn string) (driver.Connector, error)
+
+	// Hook wraps connections if it is not nil.
+	Hook dbhook.Hook
+
+	// OnConnect callbacks run in order for every new connection.
+	OnConnect []OnConnectFunc
+}
+
+// Connector opens connections with the registered driver and wraps them
+// with hooks. It lets database/sql use hooks without registering
+// a wrapped driver globally.
+type Connector struct {
+	driver    driver.Driver
+	hook      dbhook.Hook
+	onConnect []OnConnectFunc
+	dsnFunc   func(ctx context.Context) (string, error)
+	open      func(dsn string) (driver.Connector, error)
+
+	mu   sync.Mutex
+	dsn  string
+	base driver.Connector
+}
+
+// NewConnector returns connector for the driver registered with cfg.DriverName.
+func NewConnector(cfg Config) (*Connector, error) {
+	drv, err := lookupDriver(cfg.DriverName)
+	if err != nil {
+		return nil, err
+	}
+
+	connector := &Connector{
+		driver:    drv,
+		hook:      cfg.Hook,
+		onConnect: cfg.OnConnect,
+		dsnFunc:   cfg.DataSourceFunc,
+		open:      cfg.OpenConnector,
+	}
+
+	if connector.open == nil {
+		connector.open = func(dsn string) (driver.Connector, error) {
+			return openConnector(drv, dsn)
+		}
+	}
+
+	if cfg.DataSourceFunc == nil {
+		if connector.base, err = connector.open(cfg.DataSourceName); err != nil {
+			return nil, err
+		}
+	}
+
+	return connector, nil
+}
+
+// Connect returns a new connection wrapped with hooks and initialized
+// with on connect callbacks. Errors of the callbacks wrap
+// dberrors.ErrConnectionInit.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	base, err := c.connector(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", dberrors.ErrConnectionInit, err)
+	}
+
+	conn, err := base.Connect(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // need clean err.
+	}
+
+	if c.hook != nil {
+		// dbhook wraps connections opened by a driver, so hand the
+		// connection over through a driver that returns it as is.
+		conn, _ = dbhook.Wrap(connDriver{conn: conn}, c.hook).Open("") // never fails.
+	}
+
+	for _, fn := range c.onConnect {
+		if err := fn(ctx, &Session{conn: conn}); err != nil {
+			_ = conn.Close()
+
+			return nil, fmt.Errorf("%w: %w", dberrors.ErrConnectionInit, err)
+		}
+	}
+
+	return conn, nil
+}
+
+// Driver returns the original driver.
+func (c *Connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close closes the original connector if it is closable. It is called by sql.DB.Close.
+func (c *Connector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return closeConnector(c.base)
+}
+
+// connector returns the original connector for the current data source name.
+// The connector is reopened when data source name changes.
+func (c *Connector) connector(ctx context.Context) (driver.Connector, error) {
+	if c.dsnFunc == nil {
+		return c.base, nil
+	}
+
+	dsn, err := c.dsnFunc(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.base != nil && dsn == c.dsn {
+		return c.base, nil
+	}
+
+	base, err := c.open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connectors of drivers don't own connections they opened,
+	// so the replaced one is closed right away.
+	_ = closeConnector(c.base)
+
+	c.dsn, c.base = dsn, base
+
+	return base, nil
+}
+
+func closeConnector(connector driver.Connector) error {
+	if closer, ok := connector.(io.Closer); ok {
+		return closer.Close() //nolint:wrapcheck // need clean err.
+	}
+
+	return nil
+}
+
+func openConnector(drv driver.Driver, dsn string) (driver.Connector, error) {
+	if driverCtx, ok := drv.(driver.DriverContext); ok {
+		connector, err := driverCtx.OpenConnector(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("can't open connector: %w", err)
+		}
+
+		return connector, nil
+	}
+
+	return dsnConnector{dsn: dsn, driver: drv}, nil
+}
+
+// lookupDriver returns the driver registered with driverName. database/sql
+// has no lookup by name, so the driver is taken from a db opened without
+// connections once and cached.
+func lookupDriver(driverName string) (driver.Driver, error) {
+	if drv, ok := drivers.Load(driverName); ok {
+		return drv.(driver.Driver), nil //nolint:forcetypeassert // only drivers are stored.
+	}
+
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, fmt.Errorf("can't find original driver: %w", err)
+	}
+
+	drv := db.Driver()
+
+	_ = db.Close()
+
+	drivers.Store(driverName, drv)
+
+	return drv, nil
+}
+
+// dsnConnector is a connector for drivers that don't implement driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn) //nolint:wrapcheck // need clean err.
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// connDriver returns the connection it holds.
+type connDriver struct {
+	conn driver.Conn
+}
+
+func (d connDriver) Open(string) (driver.Conn, error) {
+	return d.conn, nil
+}
@@ -0,0 +1,53 @@
+package dbsqlx
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closerConnector struct {
+	driver.Connector
+	closed bool
+}
+
+func (c *closerConnector) Close() error {
+	c.closed = true
+
+	return nil
+}
+
+func TestConnector_connector(t *testing.T) {
+	var (
+		dsn    = "first"
+		opened []*closerConnector
+	)
+
+	connector, err := NewConnector(Config{
+		DriverName:     "sqlite3",
+		DataSourceFunc: func(ctx context.Context) (string, error) { return dsn, nil },
+		OpenConnector: func(dsn string) (driver.Connector, error) {
+			opened = append(opened, &closerConnector{})
+
+			return opened[len(opened)-1], nil
+		},
+	})
+	require.NoError(t, err)
+
+	for _, dsn = range []string{"first", "first", "second", "third"} {
+		_, err := connector.connector(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Len(t, opened, 3, "connector must be reopened only when dsn changes")
+	assert.True(t, opened[0].closed, "replaced connector must be closed")
+	assert.True(t, opened[1].closed, "replaced connector must be closed")
+	assert.False(t, opened[2].closed)
+
+	require.NoError(t, connector.Close())
+	assert.True(t, opened[2].closed)
+}
@@ -0,0 +1,50 @@
+package dbsqlx
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/loghole/dbhook"
+)
+
+// Ping verifies a connection to the database with a round trip to the server.
+//
+// Connections wrapped with dbhook don't implement driver.Pinger, so
+// sql.DB.PingContext only takes a connection from the pool. Ping unwraps
+// the connection and calls driver ping directly.
+func Ping(ctx context.Context, db *sqlx.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get conn: %w", err)
+	}
+
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		if pinger, ok := UnwrapConn(driverConn).(driver.Pinger); ok {
+			return pinger.Ping(ctx)
+		}
+
+		return nil
+	})
+}
+
+// UnwrapConn returns original driver connection of dbhook wrapped connection.
+func UnwrapConn(driverConn interface{}) interface{} {
+	switch conn := driverConn.(type) {
+	case *dbhook.ExecerQueryerSessionResetter:
+		return conn.Conn.Conn
+	case *dbhook.ExecerQueryer:
+		return conn.Conn.Conn
+	case *dbhook.ExecerContext:
+		return conn.Conn.Conn
+	case *dbhook.QueryerContext:
+		return conn.Conn.Conn
+	case *dbhook.Conn:
+		return conn.Conn
+	default:
+		return driverConn
+	}
+}
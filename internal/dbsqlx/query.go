@@ -0,0 +1,110 @@
+package dbsqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueryRow runs query on the original driver connection of db and scans
+// the first row into dest. Hooks are bypassed, so internal queries
+// are not traced, observed or retried as queries of the user.
+func QueryRow(ctx context.Context, db *sqlx.DB, query string, dest ...sql.Scanner) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get conn: %w", err)
+	}
+
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		rows, err := queryRaw(ctx, UnwrapConn(driverConn), query)
+		if err != nil {
+			return err
+		}
+
+		defer rows.Close()
+
+		values := make([]driver.Value, len(rows.Columns()))
+
+		if err := rows.Next(values); err != nil {
+			if errors.Is(err, io.EOF) {
+				return sql.ErrNoRows
+			}
+
+			return err //nolint:wrapcheck // need clean err.
+		}
+
+		if len(values) < len(dest) {
+			return fmt.Errorf("expected %d columns, got %d", len(dest), len(values))
+		}
+
+		for i, scanner := range dest {
+			if err := scanner.Scan(values[i]); err != nil {
+				return fmt.Errorf("scan column %d: %w", i, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func queryRaw(ctx context.Context, conn interface{}, query string) (driver.Rows, error) {
+	if queryer, ok := conn.(driver.QueryerContext); ok {
+		rows, err := queryer.QueryContext(ctx, query, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return rows, err //nolint:wrapcheck // need clean err.
+		}
+	}
+
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+
+	switch preparer := conn.(type) {
+	case driver.ConnPrepareContext:
+		stmt, err = preparer.PrepareContext(ctx, query)
+	case driver.Conn:
+		stmt, err = preparer.Prepare(query)
+	default:
+		return nil, fmt.Errorf("unsupported connection %T", conn)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("prepare: %w", err)
+	}
+
+	var rows driver.Rows
+
+	if queryer, ok := stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, nil)
+	} else {
+		rows, err = stmt.Query(nil) //nolint:staticcheck // fallback for old drivers.
+	}
+
+	if err != nil {
+		_ = stmt.Close()
+
+		return nil, err //nolint:wrapcheck // need clean err.
+	}
+
+	return stmtRows{Rows: rows, stmt: stmt}, nil
+}
+
+// stmtRows closes the statement with rows.
+type stmtRows struct {
+	driver.Rows
+	stmt driver.Stmt
+}
+
+func (r stmtRows) Close() error {
+	err := r.Rows.Close()
+
+	return errors.Join(err, r.stmt.Close())
+}
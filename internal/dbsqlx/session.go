@@ -0,0 +1,68 @@
+package dbsqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// Session is a new connection passed to on connect callbacks.
+type Session struct {
+	conn driver.Conn
+}
+
+// ExecContext executes a query without returning any rows on the connection.
+func (s *Session) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	named := make([]driver.NamedValue, len(args))
+
+	for i, arg := range args {
+		val, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			return nil, fmt.Errorf("convert argument %d: %w", i, err)
+		}
+
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: val}
+	}
+
+	if execer, ok := s.conn.(driver.ExecerContext); ok {
+		result, err := execer.ExecContext(ctx, query, named)
+		if !errors.Is(err, driver.ErrSkip) {
+			return result, err //nolint:wrapcheck // need clean err.
+		}
+	}
+
+	return s.execStmt(ctx, query, named)
+}
+
+func (s *Session) execStmt(ctx context.Context, query string, named []driver.NamedValue) (sql.Result, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+
+	if preparer, ok := s.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = s.conn.Prepare(query)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("prepare: %w", err)
+	}
+
+	defer stmt.Close()
+
+	if execer, ok := stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, named) //nolint:wrapcheck // need clean err.
+	}
+
+	values := make([]driver.Value, len(named))
+
+	for i, arg := range named {
+		values[i] = arg.Value
+	}
+
+	return stmt.Exec(values) //nolint:wrapcheck,staticcheck // fallback for old drivers.
+}
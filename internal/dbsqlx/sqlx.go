@@ -4,22 +4,32 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strings"
 
 	"github.com/jmoiron/sqlx"
 )
 
-func NewSQLx(driverName, dataSourceName string) (*sqlx.DB, error) {
-	stdDB, err := sql.Open(driverName, dataSourceName)
+// Open opens db without establishing any connections.
+func Open(cfg Config) (*sqlx.DB, error) {
+	connector, err := NewConnector(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("can't open db: %w", err)
 	}
 
-	if err := stdDB.PingContext(context.TODO()); err != nil {
-		return nil, fmt.Errorf("can't ping db: %w", err)
+	return sqlx.NewDb(sql.OpenDB(connector), cfg.DriverName), nil
+}
+
+// NewSQLx opens db and verifies connection with ping.
+func NewSQLx(ctx context.Context, cfg Config) (*sqlx.DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	db := sqlx.NewDb(stdDB, strings.Split(driverName, "-")[0])
+	if err := Ping(ctx, db); err != nil {
+		_ = db.Close()
+
+		return nil, fmt.Errorf("can't ping db: %w", err)
+	}
 
 	return db, nil
 }
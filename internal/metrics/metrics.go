@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -12,41 +14,116 @@ import (
 //nolint:gochecknoglobals // singleton object.
 var (
 	_metrics     *Metrics
-	_metricsMu   sync.Mutex
+	_metricsErr  error
 	_metricsOnce sync.Once
 )
 
+// DefaultBuckets are buckets of query duration histogram in milliseconds.
+//
+//nolint:gochecknoglobals // constant list.
+var DefaultBuckets = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Options configures registration of metrics.
+type Options struct {
+	// Registerer registers metrics, prometheus.DefaultRegisterer by default.
+	Registerer prometheus.Registerer
+
+	Namespace string
+	Subsystem string
+
+	// ConstLabels are added to all metrics, e.g. to tell apart
+	// databases with the same address.
+	ConstLabels prometheus.Labels
+
+	// Buckets of query duration histogram in milliseconds. Query duration
+	// is a summary unless Buckets or NativeHistogramBucketFactor are set.
+	Buckets []float64
+
+	// NativeHistogramBucketFactor enables native histogram of query duration,
+	// see prometheus.HistogramOpts.
+	NativeHistogramBucketFactor float64
+}
+
 type Metrics struct {
-	queryDuration        *prometheus.SummaryVec
+	queryDuration        prometheus.ObserverVec
 	serializationFailure *prometheus.CounterVec
+	poolStats            *poolStatsCollector
+	retries              *prometheus.CounterVec
+	reconnects           *prometheus.CounterVec
+	transactionDuration  prometheus.ObserverVec
+	rowsReturned         *prometheus.CounterVec
+	rowsAffected         *prometheus.CounterVec
 }
 
+// NewMetrics returns metrics registered in prometheus.DefaultRegisterer.
 func NewMetrics() (*Metrics, error) {
-	_metricsMu.Lock()
-	defer _metricsMu.Unlock()
+	_metricsOnce.Do(func() {
+		_metrics, _metricsErr = New(&Options{})
+	})
+
+	return _metrics, _metricsErr
+}
 
-	var err error
+// New registers metrics with options. Metrics which are already registered
+// with the same options are reused, so several databases can share them.
+func New(opts *Options) (*Metrics, error) {
+	reg := opts.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
 
-	_metricsOnce.Do(func() {
-		_metrics = &Metrics{
-			queryDuration:        queryDurationSummaryVec(),
-			serializationFailure: serializationFailureCounterVec(),
-		}
+	var (
+		metrics = &Metrics{}
+		err     error
+	)
 
-		if perr := prometheus.Register(_metrics.queryDuration); perr != nil {
-			err = fmt.Errorf("register 'query_duration' metric: %w", perr)
+	if metrics.queryDuration, err = register(reg, queryDurationVec(opts)); err != nil {
+		return nil, fmt.Errorf("register 'query_duration' metric: %w", err)
+	}
 
-			return
-		}
+	if metrics.serializationFailure, err = register(reg, serializationFailureCounterVec(opts)); err != nil {
+		return nil, fmt.Errorf("register 'serialization_failure' metric: %w", err)
+	}
+
+	if metrics.poolStats, err = register(reg, newPoolStatsCollector(opts)); err != nil {
+		return nil, fmt.Errorf("register 'pool_stats' metrics: %w", err)
+	}
+
+	if metrics.retries, err = register(reg, retryCounterVec(opts)); err != nil {
+		return nil, fmt.Errorf("register 'retries' metric: %w", err)
+	}
+
+	if metrics.reconnects, err = register(reg, reconnectCounterVec(opts)); err != nil {
+		return nil, fmt.Errorf("register 'reconnects' metric: %w", err)
+	}
+
+	if metrics.transactionDuration, err = register(reg, transactionDurationVec(opts)); err != nil {
+		return nil, fmt.Errorf("register 'transaction_duration' metric: %w", err)
+	}
+
+	if metrics.rowsReturned, err = register(reg, rowsReturnedCounterVec(opts)); err != nil {
+		return nil, fmt.Errorf("register 'rows_returned' metric: %w", err)
+	}
 
-		if perr := prometheus.Register(_metrics.serializationFailure); perr != nil {
-			err = fmt.Errorf("register 'serialization_failure' metric: %w", perr)
+	if metrics.rowsAffected, err = register(reg, rowsAffectedCounterVec(opts)); err != nil {
+		return nil, fmt.Errorf("register 'rows_affected' metric: %w", err)
+	}
 
-			return
+	return metrics, nil
+}
+
+// register registers collector or returns already registered one.
+func register[T prometheus.Collector](reg prometheus.Registerer, collector T) (T, error) {
+	err := reg.Register(collector)
+
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing, nil
 		}
-	})
+	}
 
-	return _metrics, err
+	return collector, err //nolint:wrapcheck // wrapped by caller.
 }
 
 func (m *Metrics) SerializationFailureInc(dbType, dbAddr, dbName string) {
@@ -57,6 +134,14 @@ func (m *Metrics) SerializationFailureInc(dbType, dbAddr, dbName string) {
 	}).Inc()
 }
 
+func (m *Metrics) PoolStatsObserve(dbType, dbAddr, dbName string, stats sql.DBStats) { //nolint:gocritic // sql.DBStats is passed by value in database/sql.
+	m.poolStats.observe(dbType, dbAddr, dbName, stats)
+}
+
+func (m *Metrics) PoolStatsDelete(dbType, dbAddr, dbName string) {
+	m.poolStats.delete(dbType, dbAddr, dbName)
+}
+
 func (m *Metrics) QueryDurationObserve(
 	dbType,
 	dbAddr,
@@ -76,24 +161,141 @@ func (m *Metrics) QueryDurationObserve(
 	}).Observe(float64(since) / float64(time.Millisecond))
 }
 
+func (m *Metrics) RetryInc(dbType, dbAddr, dbName, operation string) {
+	m.retries.With(prometheus.Labels{
+		"db_type":   dbType,
+		"db_addr":   dbAddr,
+		"db_name":   dbName,
+		"operation": operation,
+	}).Inc()
+}
+
+func (m *Metrics) ReconnectInc(dbType, dbAddr, dbName string, isError bool) {
+	m.reconnects.With(prometheus.Labels{
+		"db_type":  dbType,
+		"db_addr":  dbAddr,
+		"db_name":  dbName,
+		"is_error": strconv.FormatBool(isError),
+	}).Inc()
+}
+
+func (m *Metrics) TransactionDurationObserve(dbType, dbAddr, dbName string, isCommit bool, since time.Duration) {
+	m.transactionDuration.With(prometheus.Labels{
+		"db_type":   dbType,
+		"db_addr":   dbAddr,
+		"db_name":   dbName,
+		"is_commit": strconv.FormatBool(isCommit),
+	}).Observe(float64(since) / float64(time.Millisecond))
+}
+
+func (m *Metrics) RowsReturnedObserve(dbType, dbAddr, dbName, operation, table string, rows int64) {
+	m.rowsReturned.With(rowsLabels(dbType, dbAddr, dbName, operation, table)).Add(float64(rows))
+}
+
+func (m *Metrics) RowsAffectedObserve(dbType, dbAddr, dbName, operation, table string, rows int64) {
+	m.rowsAffected.With(rowsLabels(dbType, dbAddr, dbName, operation, table)).Add(float64(rows))
+}
+
+func rowsLabels(dbType, dbAddr, dbName, operation, table string) prometheus.Labels {
+	return prometheus.Labels{
+		"db_type":   dbType,
+		"db_addr":   dbAddr,
+		"db_name":   dbName,
+		"operation": operation,
+		"table":     table,
+	}
+}
+
 //nolint:promlinter // skip milliseconds.
-func queryDurationSummaryVec() *prometheus.SummaryVec {
+func queryDurationVec(opts *Options) prometheus.ObserverVec {
+	return durationVec(opts, "sql_query_duration_milliseconds", "response time for SQL queries (milliseconds)",
+		[]string{"db_type", "db_addr", "db_name", "is_error", "operation", "table"})
+}
+
+//nolint:promlinter // skip milliseconds.
+func transactionDurationVec(opts *Options) prometheus.ObserverVec {
+	return durationVec(opts, "sql_transaction_duration_milliseconds",
+		"duration of SQL transactions from begin to commit or rollback (milliseconds)",
+		[]string{"db_type", "db_addr", "db_name", "is_commit"})
+}
+
+// durationVec returns summary unless histogram is enabled by options.
+func durationVec(opts *Options, name, help string, labels []string) prometheus.ObserverVec {
+	if len(opts.Buckets) == 0 && opts.NativeHistogramBucketFactor == 0 {
+		return summaryVec(opts, name, help, labels)
+	}
+
+	return histogramVec(opts, name, help, labels)
+}
+
+func summaryVec(opts *Options, name, help string, labels []string) *prometheus.SummaryVec {
 	return prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
-			Name:       "sql_query_duration_milliseconds",
-			Help:       "Summary of response time for SQL queries (milliseconds)",
-			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}, //nolint:gomnd // it's ok
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        name,
+			Help:        "Summary of " + help,
+			Objectives:  map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}, //nolint:gomnd // it's ok
+			ConstLabels: opts.ConstLabels,
+		},
+		labels,
+	)
+}
+
+func histogramVec(opts *Options, name, help string, labels []string) *prometheus.HistogramVec {
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:                   opts.Namespace,
+			Subsystem:                   opts.Subsystem,
+			Name:                        name,
+			Help:                        "Histogram of " + help,
+			Buckets:                     buckets,
+			NativeHistogramBucketFactor: opts.NativeHistogramBucketFactor,
+			ConstLabels:                 opts.ConstLabels,
 		},
-		[]string{"db_type", "db_addr", "db_name", "is_error", "operation", "table"},
+		labels,
 	)
 }
 
-func serializationFailureCounterVec() *prometheus.CounterVec {
+func serializationFailureCounterVec(opts *Options) *prometheus.CounterVec {
+	return counterVec(opts, "sql_serialization_failure_errors_total", "SQL transaction serialization failure count",
+		[]string{"db_type", "db_addr", "db_name"})
+}
+
+func retryCounterVec(opts *Options) *prometheus.CounterVec {
+	return counterVec(opts, "sql_retries_total", "The total number of retried SQL operations",
+		[]string{"db_type", "db_addr", "db_name", "operation"})
+}
+
+func reconnectCounterVec(opts *Options) *prometheus.CounterVec {
+	return counterVec(opts, "sql_reconnects_total", "The total number of reconnect attempts",
+		[]string{"db_type", "db_addr", "db_name", "is_error"})
+}
+
+func rowsReturnedCounterVec(opts *Options) *prometheus.CounterVec {
+	return counterVec(opts, "sql_rows_returned_total", "The total number of rows returned by SQL queries",
+		[]string{"db_type", "db_addr", "db_name", "operation", "table"})
+}
+
+func rowsAffectedCounterVec(opts *Options) *prometheus.CounterVec {
+	return counterVec(opts, "sql_rows_affected_total", "The total number of rows affected by SQL queries",
+		[]string{"db_type", "db_addr", "db_name", "operation", "table"})
+}
+
+func counterVec(opts *Options, name, help string, labels []string) *prometheus.CounterVec {
 	return prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "sql_serialization_failure_errors_total",
-			Help: "SQL transaction serialization failure count",
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        name,
+			Help:        help,
+			ConstLabels: opts.ConstLabels,
 		},
-		[]string{"db_type", "db_addr", "db_name"},
+		labels,
 	)
 }
@@ -1,11 +1,14 @@
 package metrics
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,7 +25,7 @@ func TestNewMetrics(t *testing.T) {
 
 func TestMetrics_QueryDurationObserve(t *testing.T) {
 	type fields struct {
-		queryDuration        *prometheus.SummaryVec
+		queryDuration        prometheus.ObserverVec
 		serializationFailure *prometheus.CounterVec
 	}
 	type args struct {
@@ -42,7 +45,7 @@ func TestMetrics_QueryDurationObserve(t *testing.T) {
 		{
 			name: "pass",
 			fields: fields{
-				queryDuration: queryDurationSummaryVec(),
+				queryDuration: queryDurationVec(&Options{}),
 			},
 			args: args{
 				dbType:    "1",
@@ -67,7 +70,7 @@ func TestMetrics_QueryDurationObserve(t *testing.T) {
 
 func TestMetrics_SerializationFailureInc(t *testing.T) {
 	type fields struct {
-		queryDuration        *prometheus.SummaryVec
+		queryDuration        prometheus.ObserverVec
 		serializationFailure *prometheus.CounterVec
 	}
 	type args struct {
@@ -83,7 +86,7 @@ func TestMetrics_SerializationFailureInc(t *testing.T) {
 		{
 			name: "pass",
 			fields: fields{
-				serializationFailure: serializationFailureCounterVec(),
+				serializationFailure: serializationFailureCounterVec(&Options{}),
 			},
 			args: args{
 				dbType: "1",
@@ -102,3 +105,174 @@ func TestMetrics_SerializationFailureInc(t *testing.T) {
 		})
 	}
 }
+
+func TestMetrics_PoolStatsObserve(t *testing.T) {
+	m := &Metrics{
+		poolStats: newPoolStatsCollector(&Options{}),
+	}
+
+	m.PoolStatsObserve("1", "2", "3", sql.DBStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    3,
+		InUse:              2,
+		Idle:               1,
+		WaitCount:          5,
+		WaitDuration:       time.Second,
+	})
+
+	expected := `
+		# HELP sql_pool_in_use_connections The number of connections currently in use
+		# TYPE sql_pool_in_use_connections gauge
+		sql_pool_in_use_connections{db_addr="2",db_name="3",db_type="1"} 2
+		# HELP sql_pool_wait_count_total The total number of connections waited for
+		# TYPE sql_pool_wait_count_total counter
+		sql_pool_wait_count_total{db_addr="2",db_name="3",db_type="1"} 5
+		# HELP sql_pool_wait_duration_seconds_total The total time blocked waiting for a new connection
+		# TYPE sql_pool_wait_duration_seconds_total counter
+		sql_pool_wait_duration_seconds_total{db_addr="2",db_name="3",db_type="1"} 1
+	`
+
+	require.NoError(t, testutil.CollectAndCompare(m.poolStats, strings.NewReader(expected),
+		"sql_pool_in_use_connections", "sql_pool_wait_count_total", "sql_pool_wait_duration_seconds_total"))
+	assert.Equal(t, 9, testutil.CollectAndCount(m.poolStats))
+
+	problems, err := testutil.CollectAndLint(m.poolStats)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestMetrics_PoolStatsDelete(t *testing.T) {
+	m := &Metrics{
+		poolStats: newPoolStatsCollector(&Options{}),
+	}
+
+	m.PoolStatsObserve("1", "2", "3", sql.DBStats{})
+	m.PoolStatsObserve("1", "4", "3", sql.DBStats{})
+
+	m.PoolStatsDelete("1", "2", "3")
+
+	expected := `
+		# HELP sql_pool_in_use_connections The number of connections currently in use
+		# TYPE sql_pool_in_use_connections gauge
+		sql_pool_in_use_connections{db_addr="4",db_name="3",db_type="1"} 0
+	`
+
+	require.NoError(t, testutil.CollectAndCompare(m.poolStats, strings.NewReader(expected),
+		"sql_pool_in_use_connections"))
+}
+
+func TestNew(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+
+	first, err := New(&Options{Registerer: reg, ConstLabels: prometheus.Labels{"service": "first"}})
+	require.NoError(t, err)
+
+	reused, err := New(&Options{Registerer: reg, ConstLabels: prometheus.Labels{"service": "first"}})
+	require.NoError(t, err)
+
+	assert.Same(t, first.queryDuration, reused.queryDuration, "metrics must be reused")
+	assert.Same(t, first.poolStats, reused.poolStats, "metrics must be reused")
+
+	second, err := New(&Options{Registerer: reg, ConstLabels: prometheus.Labels{"service": "second"}})
+	require.NoError(t, err)
+
+	assert.NotSame(t, first.poolStats, second.poolStats)
+
+	first.QueryDurationObserve("1", "2", "3", "select", "t", false, time.Millisecond)
+	second.QueryDurationObserve("1", "2", "3", "select", "t", true, time.Millisecond)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(reg, "sql_query_duration_milliseconds"))
+
+	_, err = New(&Options{Registerer: reg, Buckets: DefaultBuckets})
+	assert.Error(t, err, "summary and histogram must not be mixed")
+}
+
+func TestNew_histogram(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "buckets",
+			opts: Options{Namespace: "app", Subsystem: "db", Buckets: []float64{1, 10}},
+			want: `
+				# HELP app_db_sql_query_duration_milliseconds Histogram of response time for SQL queries (milliseconds)
+				# TYPE app_db_sql_query_duration_milliseconds histogram
+				app_db_sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="1"} 0
+				app_db_sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="10"} 1
+				app_db_sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="+Inf"} 1
+				app_db_sql_query_duration_milliseconds_sum{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t"} 5
+				app_db_sql_query_duration_milliseconds_count{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t"} 1
+			`,
+		},
+		{
+			name: "native",
+			opts: Options{NativeHistogramBucketFactor: 1.1},
+			want: `
+				# HELP sql_query_duration_milliseconds Histogram of response time for SQL queries (milliseconds)
+				# TYPE sql_query_duration_milliseconds histogram
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="1"} 0
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="2.5"} 0
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="5"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="10"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="25"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="50"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="100"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="250"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="500"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="1000"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="2500"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="5000"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="10000"} 1
+				sql_query_duration_milliseconds_bucket{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t",le="+Inf"} 1
+				sql_query_duration_milliseconds_sum{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t"} 5
+				sql_query_duration_milliseconds_count{db_addr="2",db_name="3",db_type="1",is_error="false",operation="select",table="t"} 1
+			`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewPedanticRegistry()
+
+			tt.opts.Registerer = reg
+
+			m, err := New(&tt.opts)
+			require.NoError(t, err)
+
+			m.QueryDurationObserve("1", "2", "3", "select", "t", false, 5*time.Millisecond)
+
+			assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(tt.want),
+				prometheus.BuildFQName(tt.opts.Namespace, tt.opts.Subsystem, "sql_query_duration_milliseconds")))
+		})
+	}
+}
+
+func TestMetrics_extended(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+
+	m, err := New(&Options{Registerer: reg})
+	require.NoError(t, err)
+
+	m.RetryInc("1", "2", "3", "ExecContext")
+	m.RetryInc("1", "2", "3", "ExecContext")
+	m.ReconnectInc("1", "2", "3", true)
+	m.TransactionDurationObserve("1", "2", "3", true, time.Second)
+	m.TransactionDurationObserve("1", "2", "3", false, time.Second)
+	m.RowsReturnedObserve("1", "2", "3", "select", "t", 5)
+	m.RowsAffectedObserve("1", "2", "3", "insert", "t", 2)
+	m.RowsAffectedObserve("1", "2", "3", "insert", "t", 3)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.retries.WithLabelValues("1", "2", "3", "ExecContext")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.reconnects.WithLabelValues("1", "2", "3", "true")))
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.rowsReturned.WithLabelValues("1", "2", "3", "select", "t")))
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.rowsAffected.WithLabelValues("1", "2", "3", "insert", "t")))
+	assert.Equal(t, 2, testutil.CollectAndCount(reg, "sql_transaction_duration_milliseconds"))
+
+	problems, err := testutil.GatherAndLint(reg)
+	require.NoError(t, err)
+
+	for _, problem := range problems {
+		assert.Contains(t, problem.Metric, "milliseconds", "only milliseconds are allowed: %s", problem.Text)
+	}
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type poolKey struct {
+	dbType, dbAddr, dbName string
+}
+
+// poolStatsCollector exports last observed pool statistics on scrape,
+// so cumulative counters of sql.DBStats are exported as counters.
+type poolStatsCollector struct {
+	mu    sync.Mutex
+	stats map[poolKey]sql.DBStats
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newPoolStatsCollector(opts *Options) *poolStatsCollector {
+	labels := []string{"db_type", "db_addr", "db_name"}
+
+	newDesc := func(name, help string) *prometheus.Desc {
+		fqName := prometheus.BuildFQName(opts.Namespace, opts.Subsystem, name)
+
+		return prometheus.NewDesc(fqName, help, labels, opts.ConstLabels)
+	}
+
+	return &poolStatsCollector{
+		stats: make(map[poolKey]sql.DBStats),
+		maxOpen: newDesc("sql_pool_max_open_connections",
+			"Maximum number of open connections to the database"),
+		open: newDesc("sql_pool_open_connections",
+			"The number of established connections both in use and idle"),
+		inUse: newDesc("sql_pool_in_use_connections",
+			"The number of connections currently in use"),
+		idle: newDesc("sql_pool_idle_connections",
+			"The number of idle connections"),
+		waitCount: newDesc("sql_pool_wait_count_total",
+			"The total number of connections waited for"),
+		waitDuration: newDesc("sql_pool_wait_duration_seconds_total",
+			"The total time blocked waiting for a new connection"),
+		maxIdleClosed: newDesc("sql_pool_max_idle_closed_total",
+			"The total number of connections closed due to SetMaxIdleConns"),
+		maxIdleTimeClosed: newDesc("sql_pool_max_idle_time_closed_total",
+			"The total number of connections closed due to SetConnMaxIdleTime"),
+		maxLifetimeClosed: newDesc("sql_pool_max_lifetime_closed_total",
+			"The total number of connections closed due to SetConnMaxLifetime"),
+	}
+}
+
+func (c *poolStatsCollector) observe(dbType, dbAddr, dbName string, stats sql.DBStats) { //nolint:gocritic // sql.DBStats is passed by value in database/sql.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats[poolKey{dbType: dbType, dbAddr: dbAddr, dbName: dbName}] = stats
+}
+
+func (c *poolStatsCollector) delete(dbType, dbAddr, dbName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.stats, poolKey{dbType: dbType, dbAddr: dbAddr, dbName: dbName})
+}
+
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.maxOpen,
+		c.open,
+		c.inUse,
+		c.idle,
+		c.waitCount,
+		c.waitDuration,
+		c.maxIdleClosed,
+		c.maxIdleTimeClosed,
+		c.maxLifetimeClosed,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, stats := range c.stats {
+		labels := []string{key.dbType, key.dbAddr, key.dbName}
+
+		for _, metric := range []struct {
+			desc      *prometheus.Desc
+			valueType prometheus.ValueType
+			value     float64
+		}{
+			{desc: c.maxOpen, valueType: prometheus.GaugeValue, value: float64(stats.MaxOpenConnections)},
+			{desc: c.open, valueType: prometheus.GaugeValue, value: float64(stats.OpenConnections)},
+			{desc: c.inUse, valueType: prometheus.GaugeValue, value: float64(stats.InUse)},
+			{desc: c.idle, valueType: prometheus.GaugeValue, value: float64(stats.Idle)},
+			{desc: c.waitCount, valueType: prometheus.CounterValue, value: float64(stats.WaitCount)},
+			{desc: c.waitDuration, valueType: prometheus.CounterValue, value: stats.WaitDuration.Seconds()},
+			{desc: c.maxIdleClosed, valueType: prometheus.CounterValue, value: float64(stats.MaxIdleClosed)},
+			{desc: c.maxIdleTimeClosed, valueType: prometheus.CounterValue, value: float64(stats.MaxIdleTimeClosed)},
+			{desc: c.maxLifetimeClosed, valueType: prometheus.CounterValue, value: float64(stats.MaxLifetimeClosed)},
+		} {
+			ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.value, labels...)
+		}
+	}
+}
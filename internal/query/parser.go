@@ -26,6 +26,8 @@ func (o OperationType) TablePrefix() string {
 		return "exec"
 	case ExecuteType:
 		return "execute"
+	case CopyType:
+		return "copy"
 	default:
 		return ""
 	}
@@ -40,6 +42,10 @@ const (
 	ExecType    OperationType = "exec"
 	ExecuteType OperationType = "execute"
 	UpsertType  OperationType = "upsert"
+	CopyType    OperationType = "copy"
+
+	// replaceType is a MySQL upsert, it is reported as UpsertType.
+	replaceType OperationType = "replace"
 )
 
 type Operation struct {
@@ -95,6 +101,10 @@ func (p *Parser) parse(stmt string) Operation {
 		Table: "unknown",
 	}
 
+	if operation.Type == InsertType && strings.Contains(stmt, " on duplicate key update ") {
+		operation.Type = UpsertType
+	}
+
 	prefix := operation.Type.TablePrefix()
 	if prefix == "" {
 		return operation
@@ -123,8 +133,11 @@ func (p *Parser) parseOperationType(stmt string) OperationType {
 			CallType,
 			ExecType,
 			ExecuteType,
-			UpsertType:
+			UpsertType,
+			CopyType:
 			return txt
+		case replaceType:
+			return UpsertType
 		}
 	}
 
@@ -83,6 +83,36 @@ func TestParser_Parse(t *testing.T) {
 				Table: "test",
 			},
 		},
+		{
+			name: "upsert replace",
+			args: args{
+				stmt: "REPLACE INTO test (id, name) VALUES (?, ?)",
+			},
+			want: Operation{
+				Type:  UpsertType,
+				Table: "test",
+			},
+		},
+		{
+			name: "upsert on duplicate key",
+			args: args{
+				stmt: "INSERT INTO test (id, name) VALUES (?, ?)\nON DUPLICATE KEY UPDATE name = VALUES(name)",
+			},
+			want: Operation{
+				Type:  UpsertType,
+				Table: "test",
+			},
+		},
+		{
+			name: "update replace function",
+			args: args{
+				stmt: "UPDATE test SET name = REPLACE(name, 'a', 'b')",
+			},
+			want: Operation{
+				Type:  UpdateType,
+				Table: "test",
+			},
+		},
 		{
 			name: "update 1",
 			args: args{
@@ -133,6 +163,16 @@ func TestParser_Parse(t *testing.T) {
 				Table: "procedure",
 			},
 		},
+		{
+			name: "copy",
+			args: args{
+				stmt: "COPY users (id, name) FROM STDIN",
+			},
+			want: Operation{
+				Type:  CopyType,
+				Table: "users",
+			},
+		},
 		{
 			name: "unknown",
 			args: args{
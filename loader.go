@@ -0,0 +1,385 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configVar describes Config field loaded by Load.
+type configVar struct {
+	field string // field name used in ConfigError.
+	env   string // environment variable name without prefix.
+	key   string // yaml and json key.
+	set   func(cfg *Config, val string) error
+}
+
+//nolint:gochecknoglobals // constant table.
+var configVars = []configVar{
+	{field: "Addr", env: "ADDR", key: "addr", set: func(cfg *Config, val string) error {
+		cfg.Addr = val
+
+		return nil
+	}},
+	{field: "Addrs", env: "ADDRS", key: "addrs", set: func(cfg *Config, val string) error {
+		cfg.Addrs = splitList(val)
+
+		return nil
+	}},
+	{field: "ReplicaAddrs", env: "REPLICA_ADDRS", key: "replica_addrs", set: func(cfg *Config, val string) error {
+		cfg.ReplicaAddrs = splitList(val)
+
+		return nil
+	}},
+	{field: "User", env: "USER", key: "user", set: func(cfg *Config, val string) error {
+		cfg.User = val
+
+		return nil
+	}},
+	{field: "Database", env: "DATABASE", key: "database", set: func(cfg *Config, val string) error {
+		cfg.Database = val
+
+		return nil
+	}},
+	{field: "Type", env: "TYPE", key: "type", set: func(cfg *Config, val string) error {
+		cfg.Type = DBType(val)
+
+		return nil
+	}},
+	{field: "Timeouts.Connect", env: "CONNECT_TIMEOUT", key: "timeouts.connect", set: func(cfg *Config, val string) (err error) {
+		cfg.Timeouts.Connect, err = time.ParseDuration(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	{field: "Timeouts.Statement", env: "STATEMENT_TIMEOUT", key: "timeouts.statement", set: func(cfg *Config, val string) (err error) {
+		cfg.Timeouts.Statement, err = time.ParseDuration(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	{field: "Timeouts.Read", env: "READ_TIMEOUT", key: "timeouts.read", set: func(cfg *Config, val string) (err error) {
+		cfg.Timeouts.Read, err = time.ParseDuration(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	{field: "Timeouts.Write", env: "WRITE_TIMEOUT", key: "timeouts.write", set: func(cfg *Config, val string) (err error) {
+		cfg.Timeouts.Write, err = time.ParseDuration(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	{field: "Timeouts.Lock", env: "LOCK_TIMEOUT", key: "timeouts.lock", set: func(cfg *Config, val string) (err error) {
+		cfg.Timeouts.Lock, err = time.ParseDuration(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	// Deprecated fields are loaded from files only.
+	{field: "ReadTimeout", key: "read_timeout"},
+	{field: "WriteTimeout", key: "write_timeout"},
+	{field: "MaxOpenConns", env: "MAX_OPEN_CONNS", key: "max_open_conns", set: func(cfg *Config, val string) (err error) {
+		cfg.MaxOpenConns, err = strconv.Atoi(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	{field: "MaxIdleConns", env: "MAX_IDLE_CONNS", key: "max_idle_conns", set: func(cfg *Config, val string) (err error) {
+		cfg.MaxIdleConns, err = strconv.Atoi(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	{field: "ConnMaxLifetime", env: "CONN_MAX_LIFETIME", key: "conn_max_lifetime", set: func(cfg *Config, val string) (err error) {
+		cfg.ConnMaxLifetime, err = time.ParseDuration(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	{field: "ConnMaxIdleTime", env: "CONN_MAX_IDLE_TIME", key: "conn_max_idle_time", set: func(cfg *Config, val string) (err error) {
+		cfg.ConnMaxIdleTime, err = time.ParseDuration(val)
+
+		return err //nolint:wrapcheck // wrapped by caller.
+	}},
+	{field: "CertPath", env: "CERT_PATH", key: "cert_path", set: func(cfg *Config, val string) error {
+		cfg.CertPath = val
+
+		return nil
+	}},
+	{field: "TLS.Mode", env: "TLS_MODE", key: "tls.mode", set: func(cfg *Config, val string) error {
+		cfg.tlsOrNew().Mode = TLSMode(val)
+
+		return nil
+	}},
+	{field: "TLS.CAFile", env: "TLS_CA_FILE", key: "tls.ca_file", set: func(cfg *Config, val string) error {
+		cfg.tlsOrNew().CAFile = val
+
+		return nil
+	}},
+	{field: "TLS.CertFile", env: "TLS_CERT_FILE", key: "tls.cert_file", set: func(cfg *Config, val string) error {
+		cfg.tlsOrNew().CertFile = val
+
+		return nil
+	}},
+	{field: "TLS.KeyFile", env: "TLS_KEY_FILE", key: "tls.key_file", set: func(cfg *Config, val string) error {
+		cfg.tlsOrNew().KeyFile = val
+
+		return nil
+	}},
+	{field: "TLS.ServerName", env: "TLS_SERVER_NAME", key: "tls.server_name", set: func(cfg *Config, val string) error {
+		cfg.tlsOrNew().ServerName = val
+
+		return nil
+	}},
+}
+
+const (
+	_envPassword     = "PASSWORD"
+	_envPasswordFile = "PASSWORD_FILE"
+	_envParams       = "PARAMS_"
+)
+
+// Load loads Config from YAML or JSON file and overrides it with environment
+// variables with prefix. File is skipped if path is empty, environment is
+// skipped if prefix is empty. Unset fields get defaults of the database type,
+// the result is validated and ConfigError.Field names the offending
+// environment variable or file key.
+//
+// Environment variables for prefix "DB":
+//
+//	DB_TYPE, DB_ADDR, DB_ADDRS, DB_REPLICA_ADDRS, DB_USER, DB_PASSWORD,
+//	DB_PASSWORD_FILE, DB_DATABASE, DB_CONNECT_TIMEOUT, DB_STATEMENT_TIMEOUT,
+//	DB_READ_TIMEOUT, DB_WRITE_TIMEOUT, DB_LOCK_TIMEOUT, DB_MAX_OPEN_CONNS,
+//	DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME,
+//	DB_CERT_PATH, DB_TLS_MODE, DB_TLS_CA_FILE, DB_TLS_CERT_FILE,
+//	DB_TLS_KEY_FILE, DB_TLS_SERVER_NAME, DB_PARAMS_<name>.
+//
+// Lists are comma separated, durations use time.ParseDuration format.
+// JSON files are decoded as YAML, so durations may be set as "10s" in both.
+func Load(path, prefix string) (*Config, error) {
+	var (
+		cfg  = &Config{}
+		src  = configSource{prefix: envPrefix(prefix), fromFile: path != ""}
+		errs []error
+	)
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if prefix != "" {
+		src.fromEnv, errs = cfg.loadEnv(src.prefix)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	cfg.setDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, src.rename(err)
+	}
+
+	return cfg, nil
+}
+
+// LoadEnv loads Config from environment variables with prefix, see Load.
+func LoadEnv(prefix string) (*Config, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: empty environment prefix", ErrInvalidConfig)
+	}
+
+	return Load("", prefix)
+}
+
+// LoadFile loads Config from YAML or JSON file, see Load.
+func LoadFile(path string) (*Config, error) {
+	return Load(path, "")
+}
+
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, path, err)
+	}
+
+	return nil
+}
+
+// loadEnv sets fields from environment and returns set fields.
+func (cfg *Config) loadEnv(prefix string) (map[string]bool, []error) {
+	var (
+		set  = make(map[string]bool)
+		errs []error
+	)
+
+	for _, v := range configVars {
+		if v.env == "" {
+			continue
+		}
+
+		val, ok := os.LookupEnv(prefix + v.env)
+		if !ok {
+			continue
+		}
+
+		if err := v.set(cfg, val); err != nil {
+			errs = append(errs, &ConfigError{Field: prefix + v.env, Reason: err.Error()})
+
+			continue
+		}
+
+		set[v.field] = true
+	}
+
+	if password, ok := os.LookupEnv(prefix + _envPassword); ok {
+		user, _, _ := strings.Cut(cfg.User, ":")
+		cfg.User = user + ":" + password
+	}
+
+	if path, ok := os.LookupEnv(prefix + _envPasswordFile); ok {
+		cfg.Credentials = FileCredentials{PasswordFile: path}
+	}
+
+	for _, env := range os.Environ() {
+		key, val, _ := strings.Cut(env, "=")
+
+		if name := strings.TrimPrefix(key, prefix+_envParams); name != key {
+			if cfg.Params == nil {
+				cfg.Params = make(map[string]string)
+			}
+
+			cfg.Params[name] = val
+			set["Params["+name+"]"] = true
+		}
+	}
+
+	return set, errs
+}
+
+// setDefaults sets default address, user and database for the database type.
+func (cfg *Config) setDefaults() {
+	var addr, user, database string
+
+	switch cfg.Type {
+	case PostgresDatabase, PGXDatabase:
+		addr, user = "localhost:5432", "postgres"
+	case CockroachDatabase:
+		addr, user = "localhost:26257", "root"
+	case ClickhouseDatabase:
+		addr, user, database = "localhost:9000", "default", "default"
+	case MySQLDatabase:
+		addr, user = "localhost:3306", "root"
+	case SQLiteDatabase:
+		return
+	}
+
+	if cfg.Addr == "" && len(cfg.Addrs) == 0 {
+		cfg.Addr = addr
+	}
+
+	if name, password, ok := strings.Cut(cfg.User, ":"); name == "" {
+		cfg.User = user
+
+		if ok {
+			cfg.User += ":" + password
+		}
+	}
+
+	if cfg.Database == "" {
+		cfg.Database = database
+	}
+}
+
+func (cfg *Config) tlsOrNew() *TLSConfig {
+	if cfg.TLS == nil {
+		cfg.TLS = &TLSConfig{}
+	}
+
+	return cfg.TLS
+}
+
+// configSource renames ConfigError fields to environment variables
+// or file keys they are loaded from.
+type configSource struct {
+	prefix   string
+	fromEnv  map[string]bool
+	fromFile bool
+}
+
+func (s configSource) rename(err error) error {
+	joined, ok := err.(interface{ Unwrap() []error }) //nolint:errorlint // joined errors.
+	if !ok {
+		return err
+	}
+
+	errs := joined.Unwrap()
+
+	for i, err := range errs {
+		var cfgErr *ConfigError
+		if !errors.As(err, &cfgErr) {
+			continue
+		}
+
+		renamed := *cfgErr
+		renamed.Field = s.name(cfgErr.Field)
+		errs[i] = &renamed
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s configSource) name(field string) string {
+	base, index, _ := strings.Cut(field, "[")
+
+	if base == "Params" && index != "" {
+		key := strings.TrimSuffix(index, "]")
+
+		if s.fromEnv[field] || !s.fromFile {
+			return s.prefix + _envParams + key
+		}
+
+		return "params." + key
+	}
+
+	for _, v := range configVars {
+		if v.field != base {
+			continue
+		}
+
+		if v.env != "" && (s.fromEnv[base] || (!s.fromFile && s.prefix != "")) {
+			return s.prefix + v.env
+		}
+
+		return v.key
+	}
+
+	return field
+}
+
+func envPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "_") {
+		return prefix
+	}
+
+	return prefix + "_"
+}
+
+func splitList(val string) []string {
+	list := strings.Split(val, ",")
+
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+
+	return list
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("DB_TYPE", "pgx")
+	t.Setenv("DB_ADDRS", "host1:5432, host2:5432")
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("DB_DATABASE", "app")
+	t.Setenv("DB_MAX_OPEN_CONNS", "10")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "5m")
+	t.Setenv("DB_PARAMS_application_name", "service")
+
+	got, err := LoadEnv("DB")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Config{
+		Addrs:           []string{"host1:5432", "host2:5432"},
+		User:            "postgres:secret",
+		Database:        "app",
+		Type:            PGXDatabase,
+		Params:          map[string]string{"application_name": "service"},
+		MaxOpenConns:    10,
+		ConnMaxLifetime: 5 * time.Minute,
+	}, got)
+}
+
+func TestLoadEnv_errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantFields []string
+	}{
+		{
+			name:       "parse error",
+			env:        map[string]string{"APP_DB_TYPE": "pgx", "APP_DB_MAX_IDLE_CONNS": "many", "APP_DB_READ_TIMEOUT": "10"},
+			wantFields: []string{"APP_DB_READ_TIMEOUT", "APP_DB_MAX_IDLE_CONNS"},
+		},
+		{
+			name:       "unsupported timeout",
+			env:        map[string]string{"APP_DB_TYPE": "pgx", "APP_DB_WRITE_TIMEOUT": "10s"},
+			wantFields: []string{"APP_DB_WRITE_TIMEOUT"},
+		},
+		{
+			name: "validation error",
+			env: map[string]string{
+				"APP_DB_TYPE":          "clickhouse",
+				"APP_DB_ADDRS":         "host1,host2",
+				"APP_DB_LOCK_TIMEOUT":  "-1s",
+				"APP_DB_PARAMS_secure": "true",
+				"APP_DB_TLS_MODE":      "require",
+			},
+			wantFields: []string{"APP_DB_ADDR", "APP_DB_ADDRS", "APP_DB_PARAMS_secure", "APP_DB_LOCK_TIMEOUT"},
+		},
+		{
+			name:       "missing type",
+			env:        map[string]string{"APP_DB_ADDR": "host"},
+			wantFields: []string{"APP_DB_TYPE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, val := range tt.env {
+				t.Setenv(key, val)
+			}
+
+			_, err := LoadEnv("APP_DB_")
+			require.ErrorIs(t, err, ErrInvalidConfig)
+
+			assert.Equal(t, tt.wantFields, configErrorFields(t, err))
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		file       string
+		data       string
+		want       *Config
+		wantFields []string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "yaml",
+			file: "db.yaml",
+			data: `
+type: clickhouse
+addr: clickhouse:9000
+read_timeout: 10s
+timeouts:
+  connect: 5s
+  statement: 1m
+conn_max_idle_time: 1m
+params:
+  debug: "true"
+tls:
+  mode: require
+`,
+			want: &Config{
+				Addr:            "clickhouse:9000",
+				User:            "default",
+				Database:        "default",
+				Type:            ClickhouseDatabase,
+				ReadTimeout:     "10s",
+				Timeouts:        Timeouts{Connect: 5 * time.Second, Statement: time.Minute},
+				Params:          map[string]string{"debug": "true"},
+				ConnMaxIdleTime: time.Minute,
+				TLS:             &TLSConfig{Mode: TLSRequire},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "json",
+			file: "db.json",
+			data: `{"type": "cockroach", "database": "app", "max_open_conns": 5, "conn_max_lifetime": "1h"}`,
+			want: &Config{
+				Addr:            "localhost:26257",
+				User:            "root",
+				Database:        "app",
+				Type:            CockroachDatabase,
+				MaxOpenConns:    5,
+				ConnMaxLifetime: time.Hour,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:       "validation error",
+			file:       "invalid.yaml",
+			data:       "type: sqlite3\nread_timeout: 1s\ntls:\n  mode: require\n",
+			wantFields: []string{"database", "read_timeout", "TLS"},
+			wantErr:    assert.Error,
+		},
+		{
+			name:    "unknown field",
+			file:    "unknown.yaml",
+			data:    "type: sqlite3\ndatabase: :memory:\naddress: host\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "not exists",
+			file:    "",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "not-exists.yaml")
+
+			if tt.file != "" {
+				path = filepath.Join(dir, tt.file)
+				require.NoError(t, os.WriteFile(path, []byte(tt.data), 0o600))
+			}
+
+			got, err := LoadFile(path)
+			if !tt.wantErr(t, err, "LoadFile()") {
+				return
+			}
+
+			if len(tt.wantFields) > 0 {
+				assert.Equal(t, tt.wantFields, configErrorFields(t, err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoad_envOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte("type: postgres\naddr: file:5432\nuser: file\n"), 0o600))
+
+	t.Setenv("DB_ADDR", "env:5432")
+
+	got, err := Load(path, "DB")
+	require.NoError(t, err)
+
+	assert.Equal(t, "env:5432", got.Addr)
+	assert.Equal(t, "file", got.User)
+}
+
+func configErrorFields(t *testing.T, err error) []string {
+	t.Helper()
+
+	var fields []string
+
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() { //nolint:errorlint,forcetypeassert // joined errors.
+		var cfgErr *ConfigError
+
+		if assert.True(t, errors.As(err, &cfgErr)) {
+			fields = append(fields, cfgErr.Field)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,19 @@
+//go:build go1.21
+
+package database
+
+import (
+	"log/slog"
+)
+
+// LogValue implements slog.LogValuer, secrets are masked.
+func (cfg Config) LogValue() slog.Value { //nolint:gocritic // value receiver is required to log values.
+	return slog.GroupValue(
+		slog.String("type", string(cfg.Type)),
+		slog.String("addr", cfg.Addr),
+		slog.Any("addrs", cfg.Addrs),
+		slog.String("user", cfg.credentials().User),
+		slog.String("database", cfg.Database),
+		slog.String("dsn", cfg.RedactedDSN()),
+	)
+}
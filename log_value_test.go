@@ -0,0 +1,29 @@
+//go:build go1.21
+
+package database
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := &Config{
+		Addr:     "127.0.0.1:9000",
+		User:     "default:secret",
+		Database: "database",
+		Type:     ClickhouseDatabase,
+	}
+
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("connect", "db", cfg, "hooks", cfg.hookConfig())
+
+	assert.NotContains(t, buf.String(), "secret")
+	assert.Contains(t, buf.String(), `"user":"default"`)
+	assert.Contains(t, buf.String(), "password=xxxxx")
+}
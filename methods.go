@@ -3,8 +3,6 @@ package database
 import (
 	"context"
 	"database/sql"
-	"math"
-	"math/rand"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -12,27 +10,47 @@ import (
 
 // SelectContext using this DB.
 // Any placeholder parameters are replaced with supplied args.
+// The query is routed to a read replica if replicas are configured.
 func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return db.withRetry(ctx, func() error {
-		return db.DB.SelectContext(ctx, dest, query, args...)
+	err := db.do(ctx, "SelectContext", func(ctx context.Context) error {
+		return db.read(ctx, func(conn *sqlx.DB) error {
+			return conn.SelectContext(ctx, dest, query, args...)
+		})
 	})
+	if err != nil {
+		return err
+	}
+
+	db.metrics.rowsReturned(query, destLen(dest))
+
+	return nil
 }
 
 // GetContext using this DB.
 // Any placeholder parameters are replaced with supplied args.
 // An error is returned if the result set is empty.
+// The query is routed to a read replica if replicas are configured.
 func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return db.withRetry(ctx, func() error {
-		return db.DB.GetContext(ctx, dest, query, args...)
+	err := db.do(ctx, "GetContext", func(ctx context.Context) error {
+		return db.read(ctx, func(conn *sqlx.DB) error {
+			return conn.GetContext(ctx, dest, query, args...)
+		})
 	})
+	if err != nil {
+		return err
+	}
+
+	db.metrics.rowsReturned(query, 1)
+
+	return nil
 }
 
 // BindNamed binds a query using the DB driver's bindvar type.
 func (db *DB) BindNamed(query string, arg interface{}) (bound string, arglist []interface{}, err error) {
-	err = db.withRetry(context.Background(), func() error {
+	err = db.withRetry(context.Background(), "BindNamed", func() error {
 		var err error
 
-		if bound, arglist, err = db.DB.BindNamed(query, arg); err != nil {
+		if bound, arglist, err = db.SQLx().BindNamed(query, arg); err != nil {
 			return err
 		}
 
@@ -49,11 +67,18 @@ func (db *DB) BindNamed(query string, arg interface{}) (bound string, arglist []
 // back. If the context is canceled, the sql package will roll back the
 // transaction. Tx.Commit will return an error if the context provided to
 // BeginxContext is canceled.
+//
+// Duration of the transaction is not observed by hooks.TransactionCollector,
+// use RunTxx to observe it.
 func (db *DB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (tx *sqlx.Tx, err error) {
-	err = db.withRetry(ctx, func() error {
+	if opts == nil || !opts.ReadOnly {
+		db.markWrite()
+	}
+
+	err = db.doKeepContext(ctx, "BeginTxx", func(ctx context.Context) error {
 		var err error
 
-		if tx, err = db.DB.BeginTxx(ctx, opts); err != nil {
+		if tx, err = db.SQLx().BeginTxx(ctx, opts); err != nil {
 			return err
 		}
 
@@ -66,13 +91,17 @@ func (db *DB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (tx *sqlx.Tx, e
 // ExecContext executes a query without returning any rows.
 // The args are for any placeholder parameters in the query.
 func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
-	err = db.withRetry(ctx, func() error {
+	db.markWrite()
+
+	err = db.do(ctx, "ExecContext", func(ctx context.Context) error {
 		var err error
 
-		if result, err = db.DB.ExecContext(ctx, query, args...); err != nil {
+		if result, err = db.SQLx().ExecContext(ctx, query, args...); err != nil {
 			return err
 		}
 
+		db.metrics.rowsAffected(query, result)
+
 		return nil
 	})
 
@@ -82,13 +111,17 @@ func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}
 // NamedExecContext using this DB.
 // Any named placeholder parameters are replaced with fields from arg.
 func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (result sql.Result, err error) {
-	err = db.withRetry(ctx, func() error {
+	db.markWrite()
+
+	err = db.do(ctx, "NamedExecContext", func(ctx context.Context) error {
 		var err error
 
-		if result, err = db.DB.NamedExecContext(ctx, query, arg); err != nil {
+		if result, err = db.SQLx().NamedExecContext(ctx, query, arg); err != nil {
 			return err
 		}
 
+		db.metrics.rowsAffected(query, result)
+
 		return nil
 	})
 
@@ -97,15 +130,18 @@ func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{
 
 // QueryxContext queries the database and returns an *sqlx.Rows.
 // Any placeholder parameters are replaced with supplied args.
+// The query is routed to a read replica if replicas are configured.
 func (db *DB) QueryxContext(ctx context.Context, query string, args ...interface{}) (rows *sqlx.Rows, err error) {
-	err = db.withRetry(ctx, func() error {
-		var err error
+	err = db.doKeepContext(ctx, "QueryxContext", func(ctx context.Context) error {
+		return db.read(ctx, func(conn *sqlx.DB) error {
+			var err error
 
-		if rows, err = db.DB.QueryxContext(ctx, query, args...); err != nil {
-			return err
-		}
+			if rows, err = conn.QueryxContext(ctx, query, args...); err != nil {
+				return err
+			}
 
-		return nil
+			return nil
+		})
 	})
 
 	return rows, err
@@ -114,10 +150,12 @@ func (db *DB) QueryxContext(ctx context.Context, query string, args ...interface
 // NamedQueryContext using this DB.
 // Any named placeholder parameters are replaced with fields from arg.
 func (db *DB) NamedQueryContext(ctx context.Context, query string, arg interface{}) (rows *sqlx.Rows, err error) {
-	err = db.withRetry(ctx, func() error {
+	db.markWrite()
+
+	err = db.doKeepContext(ctx, "NamedQueryContext", func(ctx context.Context) error {
 		var err error
 
-		if rows, err = db.DB.NamedQueryContext(ctx, query, arg); err != nil {
+		if rows, err = db.SQLx().NamedQueryContext(ctx, query, arg); err != nil {
 			return err
 		}
 
@@ -129,10 +167,10 @@ func (db *DB) NamedQueryContext(ctx context.Context, query string, arg interface
 
 // PreparexContext returns an sqlx.Stmt instead of a sqlx.Stmt.
 func (db *DB) PreparexContext(ctx context.Context, query string) (stmt *sqlx.Stmt, err error) {
-	err = db.withRetry(ctx, func() error {
+	err = db.do(ctx, "PreparexContext", func(ctx context.Context) error {
 		var err error
 
-		if stmt, err = db.DB.PreparexContext(ctx, query); err != nil {
+		if stmt, err = db.SQLx().PreparexContext(ctx, query); err != nil {
 			return err
 		}
 
@@ -144,10 +182,10 @@ func (db *DB) PreparexContext(ctx context.Context, query string) (stmt *sqlx.Stm
 
 // PrepareNamedContext returns an sqlx.NamedStmt.
 func (db *DB) PrepareNamedContext(ctx context.Context, query string) (stmt *sqlx.NamedStmt, err error) {
-	err = db.withRetry(ctx, func() error {
+	err = db.do(ctx, "PrepareNamedContext", func(ctx context.Context) error {
 		var err error
 
-		if stmt, err = db.DB.PrepareNamedContext(ctx, query); err != nil {
+		if stmt, err = db.SQLx().PrepareNamedContext(ctx, query); err != nil {
 			return err
 		}
 
@@ -157,33 +195,32 @@ func (db *DB) PrepareNamedContext(ctx context.Context, query string) (stmt *sqlx
 	return stmt, err
 }
 
-func (db *DB) withRetry(ctx context.Context, fn func() error) error {
+// withRetry runs fn with retry policy, every retry of the operation is observed.
+func (db *DB) withRetry(ctx context.Context, name string, fn func() error) error {
 	if db.options.retryPolicy == nil {
 		return fn()
 	}
 
-	var (
-		retryPolicy = db.options.retryPolicy
+	attempt := 0
 
-		attempt int
-		err     error
-	)
-
-	for attempt = 1; attempt <= retryPolicy.MaxAttempts; attempt++ {
-		if err = fn(); err == nil || !retryPolicy.ErrIsRetryable(err) {
-			return err
+	return retry(ctx, db.options.retryPolicy, func() error {
+		if attempt++; attempt > 1 {
+			db.metrics.retry(name)
 		}
 
-		var (
-			fact = math.Pow(retryPolicy.BackoffMultiplier, float64(attempt))
-			cur  = float64(retryPolicy.InitialBackoff) * fact
-		)
+		return fn()
+	})
+}
 
-		if max := float64(retryPolicy.MaxBackoff); cur > max {
-			cur = max
+func retry(ctx context.Context, retryPolicy *RetryPolicy, fn func() error) error {
+	var err error
+
+	for attempt := 1; attempt <= retryPolicy.MaxAttempts; attempt++ {
+		if err = fn(); err == nil || !retryPolicy.ErrIsRetryable(err) {
+			return err
 		}
 
-		timer := time.NewTimer(time.Duration(rand.Int63n(int64(cur)))) //nolint:gosec // normal for this case.
+		timer := time.NewTimer(retryPolicy.backoff(attempt))
 		select {
 		case <-timer.C:
 			continue
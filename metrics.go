@@ -0,0 +1,111 @@
+package database
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+
+	"github.com/loghole/database/hooks"
+	"github.com/loghole/database/internal/query"
+)
+
+// metricCollectors passes metrics of DB operations to collectors of
+// WithMetricsHook which implement optional interfaces of hooks.MetricCollector.
+type metricCollectors struct {
+	config *hooks.Config
+	parser *query.Parser
+
+	retries      []hooks.RetryCollector
+	reconnects   []hooks.ReconnectCollector
+	transactions []hooks.TransactionCollector
+	rows         []hooks.RowsCollector
+}
+
+func newMetricCollectors(config *hooks.Config, collectors []hooks.MetricCollector) *metricCollectors {
+	m := &metricCollectors{
+		config: config,
+		parser: query.NewParser(),
+	}
+
+	for _, collector := range collectors {
+		if c, ok := collector.(hooks.RetryCollector); ok {
+			m.retries = append(m.retries, c)
+		}
+
+		if c, ok := collector.(hooks.ReconnectCollector); ok {
+			m.reconnects = append(m.reconnects, c)
+		}
+
+		if c, ok := collector.(hooks.TransactionCollector); ok {
+			m.transactions = append(m.transactions, c)
+		}
+
+		if c, ok := collector.(hooks.RowsCollector); ok {
+			m.rows = append(m.rows, c)
+		}
+	}
+
+	return m
+}
+
+func (m *metricCollectors) retry(operation string) {
+	for _, c := range m.retries {
+		c.RetryInc(m.config.Type, m.config.CurrentAddr(), m.config.Database, operation)
+	}
+}
+
+func (m *metricCollectors) reconnect(err error) {
+	for _, c := range m.reconnects {
+		c.ReconnectInc(m.config.Type, m.config.CurrentAddr(), m.config.Database, err != nil)
+	}
+}
+
+func (m *metricCollectors) transaction(isCommit bool, since time.Duration) {
+	for _, c := range m.transactions {
+		c.TransactionDurationObserve(m.config.Type, m.config.CurrentAddr(), m.config.Database, isCommit, since)
+	}
+}
+
+// rowsReturned observes the number of rows returned by query.
+func (m *metricCollectors) rowsReturned(query string, rows int) {
+	if len(m.rows) == 0 {
+		return
+	}
+
+	parsed := m.parser.Parse(query)
+
+	for _, c := range m.rows {
+		c.RowsReturnedObserve(m.config.Type, m.config.CurrentAddr(), m.config.Database,
+			parsed.Type.String(), parsed.Table, int64(rows))
+	}
+}
+
+// destLen returns length of slice dest of SelectContext,
+// sqlx truncates dest before scanning rows.
+func destLen(dest interface{}) int {
+	if val := reflect.Indirect(reflect.ValueOf(dest)); val.Kind() == reflect.Slice {
+		return val.Len()
+	}
+
+	return 0
+}
+
+// rowsAffected observes the number of rows affected by exec query.
+// Nothing is observed if the driver doesn't report it.
+func (m *metricCollectors) rowsAffected(query string, result sql.Result) {
+	if len(m.rows) == 0 {
+		return
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	parsed := m.parser.Parse(query)
+
+	for _, c := range m.rows {
+		c.RowsAffectedObserve(m.config.Type, m.config.CurrentAddr(), m.config.Database,
+			parsed.Type.String(), parsed.Table, rows)
+	}
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loghole/database/mocks"
+)
+
+func TestDB_extendedMetrics(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		ctrl      = gomock.NewController(t)
+		collector = mocks.NewMockExtendedMetricCollector(ctrl)
+		errTx     = errors.New("tx error")
+	)
+
+	collector.EXPECT().QueryDurationObserve(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any()).AnyTimes()
+
+	db, err := New(&Config{Addr: "local", Database: ":memory:", Type: SQLiteDatabase, MaxOpenConns: 1},
+		WithMetricsHook(collector),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts:       2,
+			InitialBackoff:    1,
+			MaxBackoff:        1,
+			BackoffMultiplier: 1,
+			ErrIsRetryable:    func(err error) bool { return !errors.Is(err, errTx) },
+		}),
+	)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	collector.EXPECT().RowsAffectedObserve("sqlite3", "local", ":memory:", "unknown", "unknown", int64(0))
+	collector.EXPECT().RowsAffectedObserve("sqlite3", "local", ":memory:", "insert", "t", int64(2))
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE t (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, "INSERT INTO t (id) VALUES (1), (2)")
+	require.NoError(t, err)
+
+	collector.EXPECT().RowsReturnedObserve("sqlite3", "local", ":memory:", "select", "t", int64(2))
+	collector.EXPECT().RowsReturnedObserve("sqlite3", "local", ":memory:", "select", "t", int64(1)).Times(2)
+
+	ids := []int{42} // dest is truncated by sqlx.
+
+	require.NoError(t, db.SelectContext(ctx, &ids, "SELECT id FROM t"))
+
+	var id int
+
+	require.NoError(t, db.GetContext(ctx, &id, "SELECT id FROM t LIMIT 1"))
+
+	var data []byte
+
+	require.NoError(t, db.GetContext(ctx, &data, "SELECT x'010203' FROM t LIMIT 1"))
+
+	collector.EXPECT().TransactionDurationObserve("sqlite3", "local", ":memory:", true, gomock.Any())
+	collector.EXPECT().TransactionDurationObserve("sqlite3", "local", ":memory:", false, gomock.Any())
+
+	require.NoError(t, db.RunTxx(ctx, func(ctx context.Context, tx *sqlx.Tx) error { return nil }))
+	require.ErrorIs(t, db.RunTxx(ctx, func(ctx context.Context, tx *sqlx.Tx) error { return errTx }), errTx)
+
+	collector.EXPECT().RetryInc("sqlite3", "local", ":memory:", "ExecContext")
+
+	_, err = db.ExecContext(ctx, "SELECT * FROM unknown")
+	require.ErrorIs(t, err, ErrMaxRetryAttempts)
+
+	collector.EXPECT().ReconnectInc("sqlite3", "local", ":memory:", false)
+
+	require.NoError(t, db.reconnect())
+}
+
+func TestDB_basicMetrics(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		ctrl      = gomock.NewController(t)
+		collector = mocks.NewMockMetricCollector(ctrl)
+	)
+
+	collector.EXPECT().QueryDurationObserve(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any()).Times(3)
+
+	db := memorySQLLite(t, WithMetricsHook(collector))
+
+	defer db.Close()
+
+	_, err := db.ExecContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+
+	assert.NoError(t, db.RunTxx(ctx, func(ctx context.Context, tx *sqlx.Tx) error { return nil }))
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/loghole/database/hooks (interfaces: MetricCollector)
+// Source: github.com/loghole/database/hooks (interfaces: MetricCollector,ExtendedMetricCollector)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -57,3 +57,110 @@ func (mr *MockMetricCollectorMockRecorder) SerializationFailureInc(arg0, arg1, a
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SerializationFailureInc", reflect.TypeOf((*MockMetricCollector)(nil).SerializationFailureInc), arg0, arg1, arg2)
 }
+
+// MockExtendedMetricCollector is a mock of ExtendedMetricCollector interface.
+type MockExtendedMetricCollector struct {
+	ctrl     *gomock.Controller
+	recorder *MockExtendedMetricCollectorMockRecorder
+}
+
+// MockExtendedMetricCollectorMockRecorder is the mock recorder for MockExtendedMetricCollector.
+type MockExtendedMetricCollectorMockRecorder struct {
+	mock *MockExtendedMetricCollector
+}
+
+// NewMockExtendedMetricCollector creates a new mock instance.
+func NewMockExtendedMetricCollector(ctrl *gomock.Controller) *MockExtendedMetricCollector {
+	mock := &MockExtendedMetricCollector{ctrl: ctrl}
+	mock.recorder = &MockExtendedMetricCollectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExtendedMetricCollector) EXPECT() *MockExtendedMetricCollectorMockRecorder {
+	return m.recorder
+}
+
+// QueryDurationObserve mocks base method.
+func (m *MockExtendedMetricCollector) QueryDurationObserve(arg0, arg1, arg2, arg3, arg4 string, arg5 bool, arg6 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "QueryDurationObserve", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// QueryDurationObserve indicates an expected call of QueryDurationObserve.
+func (mr *MockExtendedMetricCollectorMockRecorder) QueryDurationObserve(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryDurationObserve", reflect.TypeOf((*MockExtendedMetricCollector)(nil).QueryDurationObserve), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// ReconnectInc mocks base method.
+func (m *MockExtendedMetricCollector) ReconnectInc(arg0, arg1, arg2 string, arg3 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReconnectInc", arg0, arg1, arg2, arg3)
+}
+
+// ReconnectInc indicates an expected call of ReconnectInc.
+func (mr *MockExtendedMetricCollectorMockRecorder) ReconnectInc(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconnectInc", reflect.TypeOf((*MockExtendedMetricCollector)(nil).ReconnectInc), arg0, arg1, arg2, arg3)
+}
+
+// RetryInc mocks base method.
+func (m *MockExtendedMetricCollector) RetryInc(arg0, arg1, arg2, arg3 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RetryInc", arg0, arg1, arg2, arg3)
+}
+
+// RetryInc indicates an expected call of RetryInc.
+func (mr *MockExtendedMetricCollectorMockRecorder) RetryInc(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryInc", reflect.TypeOf((*MockExtendedMetricCollector)(nil).RetryInc), arg0, arg1, arg2, arg3)
+}
+
+// RowsAffectedObserve mocks base method.
+func (m *MockExtendedMetricCollector) RowsAffectedObserve(arg0, arg1, arg2, arg3, arg4 string, arg5 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RowsAffectedObserve", arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// RowsAffectedObserve indicates an expected call of RowsAffectedObserve.
+func (mr *MockExtendedMetricCollectorMockRecorder) RowsAffectedObserve(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RowsAffectedObserve", reflect.TypeOf((*MockExtendedMetricCollector)(nil).RowsAffectedObserve), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// RowsReturnedObserve mocks base method.
+func (m *MockExtendedMetricCollector) RowsReturnedObserve(arg0, arg1, arg2, arg3, arg4 string, arg5 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RowsReturnedObserve", arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// RowsReturnedObserve indicates an expected call of RowsReturnedObserve.
+func (mr *MockExtendedMetricCollectorMockRecorder) RowsReturnedObserve(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RowsReturnedObserve", reflect.TypeOf((*MockExtendedMetricCollector)(nil).RowsReturnedObserve), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// SerializationFailureInc mocks base method.
+func (m *MockExtendedMetricCollector) SerializationFailureInc(arg0, arg1, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SerializationFailureInc", arg0, arg1, arg2)
+}
+
+// SerializationFailureInc indicates an expected call of SerializationFailureInc.
+func (mr *MockExtendedMetricCollectorMockRecorder) SerializationFailureInc(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SerializationFailureInc", reflect.TypeOf((*MockExtendedMetricCollector)(nil).SerializationFailureInc), arg0, arg1, arg2)
+}
+
+// TransactionDurationObserve mocks base method.
+func (m *MockExtendedMetricCollector) TransactionDurationObserve(arg0, arg1, arg2 string, arg3 bool, arg4 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TransactionDurationObserve", arg0, arg1, arg2, arg3, arg4)
+}
+
+// TransactionDurationObserve indicates an expected call of TransactionDurationObserve.
+func (mr *MockExtendedMetricCollectorMockRecorder) TransactionDurationObserve(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionDurationObserve", reflect.TypeOf((*MockExtendedMetricCollector)(nil).TransactionDurationObserve), arg0, arg1, arg2, arg3, arg4)
+}
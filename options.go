@@ -1,16 +1,19 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"time"
 
 	"github.com/loghole/dbhook"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/loghole/database/dberrors"
 	"github.com/loghole/database/hooks"
-	"github.com/loghole/database/internal/helpers"
-	"github.com/loghole/database/internal/metrics"
 )
 
 const (
@@ -18,11 +21,46 @@ const (
 	DefaultRetryInitialBackoff    = time.Millisecond
 	DefaultRetryMaxBackoff        = time.Millisecond * 100
 	DefaultRetryBackoffMultiplier = 1.5
+
+	DefaultReconnectMinInterval  = time.Second
+	DefaultReconnectMaxInterval  = time.Second * 30
+	DefaultReconnectDrainTimeout = time.Second * 30
 )
 
 type options struct {
-	retryPolicy *RetryPolicy
-	hookOptions []dbhook.HookOption
+	retryPolicy     *RetryPolicy
+	reconnectPolicy ReconnectPolicy
+	hookOptions     []dbhook.HookOption
+	collectors      []hooks.MetricCollector
+
+	replicaStrategy    ReplicaStrategy
+	replicaDownTimeout time.Duration
+	readYourWrites     time.Duration
+
+	healthCheck *HealthCheckPolicy
+	startup     StartupPolicy
+	onConnect   []OnConnectFunc
+	pgxPool     *pgxPoolOptions
+	addrPolicy  AddrPolicy
+	poolStats   *poolStatsOptions
+
+	clickhouseConnector ClickhouseConnectorFunc
+}
+
+func defaultOptions() options {
+	return options{
+		reconnectPolicy: ReconnectPolicy{
+			MinInterval:  DefaultReconnectMinInterval,
+			MaxInterval:  DefaultReconnectMaxInterval,
+			DrainTimeout: DefaultReconnectDrainTimeout,
+		},
+		replicaStrategy:    RoundRobinReplicas,
+		replicaDownTimeout: DefaultReplicaDownTimeout,
+		addrPolicy: AddrPolicy{
+			Strategy:    RandomAddrs,
+			DownTimeout: DefaultAddrDownTimeout,
+		},
+	}
 }
 
 func (o *options) apply(cfg *hooks.Config, opts ...Option) error {
@@ -35,12 +73,31 @@ func (o *options) apply(cfg *hooks.Config, opts ...Option) error {
 	return nil
 }
 
+// validate checks options required by cfg.
+func (o *options) validate(cfg *Config) error {
+	tlsCfg := cfg.tlsConfig()
+
+	if cfg.Type == ClickhouseDatabase && tlsCfg != nil && tlsCfg.clickhouseCustom() && o.clickhouseConnector == nil {
+		return fmt.Errorf("%w: TLS: clickhouse certificate files, ServerName and Config require WithClickhouseConnector",
+			ErrInvalidConfig)
+	}
+
+	return nil
+}
+
 func (o *options) hook() dbhook.Hook {
-	if len(o.hookOptions) == 0 {
+	hookOptions := o.hookOptions
+
+	if o.trackPending() {
+		// Pending requests are marked first, before hooks that may fail.
+		hookOptions = append([]dbhook.HookOption{dbhook.WithHooksBefore(pendingHook{})}, hookOptions...)
+	}
+
+	if len(hookOptions) == 0 {
 		return nil
 	}
 
-	return dbhook.NewHooks(o.hookOptions...)
+	return dbhook.NewHooks(hookOptions...)
 }
 
 // Option sets options such as hooks, metrics and retry parameters, etc.
@@ -76,9 +133,37 @@ func WithTracingHook(tracer trace.Tracer) Option {
 	})
 }
 
-func WithReconnectHook() Option {
+// WithReconnectHook recreates connection pool on connection errors.
+// Errors are detected by default matchers for the database type
+// and by custom matchers if provided.
+func WithReconnectHook(matchers ...hooks.ErrorMatcher) Option {
 	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
-		opts.hookOptions = append(opts.hookOptions, dbhook.WithHooksError(hooks.NewReconnectHook(cfg)))
+		opts.hookOptions = append(opts.hookOptions, dbhook.WithHooksError(hooks.NewReconnectHook(cfg, matchers...)))
+
+		return nil
+	})
+}
+
+// SessionConn is a new connection passed to OnConnectFunc.
+type SessionConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// OnConnectFunc initializes session of a new connection, e.g. sets
+// search_path, application_name or sqlite pragmas.
+type OnConnectFunc func(ctx context.Context, conn SessionConn) error
+
+// WithOnConnect runs fn for every new connection including connections
+// created after reconnect. Queries of fn pass through hooks, so they are
+// traced by the TracingHook. If fn fails, the connection is closed and
+// the error wraps dberrors.ErrConnectionInit.
+func WithOnConnect(fn OnConnectFunc) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if fn == nil {
+			return fmt.Errorf("%w: OnConnectFunc must be non-empty", ErrInvalidConfig)
+		}
+
+		opts.onConnect = append(opts.onConnect, fn)
 
 		return nil
 	})
@@ -92,33 +177,34 @@ func WithSimplerrHook() Option {
 	})
 }
 
+// WithMetricsHook observes queries with collector. Retries, reconnects,
+// transactions and rows are observed if collector implements optional
+// interfaces, see hooks.ExtendedMetricCollector.
 func WithMetricsHook(collector hooks.MetricCollector) Option {
 	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
 		opts.hookOptions = append(opts.hookOptions, dbhook.WithHook(hooks.NewMetricsHook(cfg, collector)))
+		opts.collectors = append(opts.collectors, collector)
 
 		return nil
 	})
 }
 
-func WithPrometheusMetrics() Option {
+// WithPrometheusMetrics observes duration of queries including failed ones,
+// serialization failures, retries, reconnects, transactions and rows with
+// prometheus metrics.
+func WithPrometheusMetrics(prometheusOpts ...PrometheusOption) Option {
 	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
-		collector, err := metrics.NewMetrics()
+		collector, err := newPrometheusMetrics(prometheusOpts)
 		if err != nil {
 			return fmt.Errorf("init prometheus collector: %w", err)
 		}
 
-		hook := hooks.NewMetricsHook(cfg, collector)
-
-		opts.hookOptions = append(
-			opts.hookOptions,
-			dbhook.WithHooksBefore(hook),
-			dbhook.WithHooksAfter(hook),
-		)
-
-		return nil
+		return WithMetricsHook(collector).apply(opts, cfg)
 	})
 }
 
+// WithPQRetryFunc retries queries and transactions on serialization failures,
+// deadlocks and after reconnect. It works for lib/pq, pgx and cockroach.
 func WithPQRetryFunc(maxAttempts int) Option {
 	if maxAttempts == 0 {
 		maxAttempts = DefaultRetryAttempts
@@ -130,7 +216,7 @@ func WithPQRetryFunc(maxAttempts int) Option {
 		MaxBackoff:        DefaultRetryMaxBackoff,
 		BackoffMultiplier: DefaultRetryBackoffMultiplier,
 		ErrIsRetryable: func(err error) bool {
-			return helpers.IsSerialisationFailureErr(err) || errors.Is(err, hooks.ErrCanRetry)
+			return dberrors.IsRetryable(err) || errors.Is(err, hooks.ErrCanRetry)
 		},
 	})
 }
@@ -181,6 +267,24 @@ type RetryPolicy struct {
 	ErrIsRetryable func(err error) bool
 }
 
+// backoff returns random delay before the next attempt.
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	var (
+		fact = math.Pow(rp.BackoffMultiplier, float64(attempt))
+		cur  = float64(rp.InitialBackoff) * fact
+	)
+
+	if max := float64(rp.MaxBackoff); cur > max {
+		cur = max
+	}
+
+	if cur < 1 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(cur))) //nolint:gosec // normal for this case.
+}
+
 func (rp *RetryPolicy) validate() error {
 	if rp.MaxAttempts <= 1 {
 		return fmt.Errorf("%w: RetryPolicy: MaxAttempts must be two or greater", ErrInvalidConfig)
@@ -216,3 +320,196 @@ func WithRetryPolicy(retryPolicy RetryPolicy) Option {
 		return nil
 	})
 }
+
+// ReconnectPolicy defines how connection pool is recreated on reconnect.
+type ReconnectPolicy struct {
+	// MinInterval is the minimum interval between two reconnect attempts.
+	// Reconnects requested earlier are skipped. After every failed attempt
+	// the interval is doubled up to MaxInterval.
+	//
+	// These fields are required and must be greater than zero.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+
+	// DrainTimeout is the maximum time to wait for in-use connections
+	// of the replaced pool before it is closed.
+	//
+	// This field must not be negative.
+	DrainTimeout time.Duration
+}
+
+func (rp *ReconnectPolicy) validate() error {
+	if rp.MinInterval <= 0 {
+		return fmt.Errorf("%w: ReconnectPolicy: MinInterval must be greater than zero", ErrInvalidConfig)
+	}
+
+	if rp.MaxInterval < rp.MinInterval {
+		return fmt.Errorf("%w: ReconnectPolicy: MaxInterval must be greater than or equal to MinInterval", ErrInvalidConfig)
+	}
+
+	if rp.DrainTimeout < 0 {
+		return fmt.Errorf("%w: ReconnectPolicy: DrainTimeout must not be negative", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// WithReconnectPolicy sets reconnect policy used by reconnect hook.
+func WithReconnectPolicy(reconnectPolicy ReconnectPolicy) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if err := reconnectPolicy.validate(); err != nil {
+			return err
+		}
+
+		opts.reconnectPolicy = reconnectPolicy
+
+		return nil
+	})
+}
+
+// WithReplicaStrategy sets strategy of replica selection for read queries
+// and the time for which replica with broken connection is excluded from routing.
+// Replicas are configured with Config.ReplicaAddrs.
+func WithReplicaStrategy(strategy ReplicaStrategy, downTimeout time.Duration) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if downTimeout <= 0 {
+			return fmt.Errorf("%w: replica down timeout must be greater than zero", ErrInvalidConfig)
+		}
+
+		opts.replicaStrategy = strategy
+		opts.replicaDownTimeout = downTimeout
+
+		return nil
+	})
+}
+
+// WithAddrPolicy sets selection of a node of Config.Addrs for connection pools.
+// By default a random node is selected and failed node is excluded for
+// DefaultAddrDownTimeout.
+func WithAddrPolicy(policy AddrPolicy) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+
+		opts.addrPolicy = policy
+
+		return nil
+	})
+}
+
+// WithReadYourWrites routes read queries to the primary during window
+// after each write so that reads observe the written data.
+func WithReadYourWrites(window time.Duration) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if window < 0 {
+			return fmt.Errorf("%w: read your writes window must not be negative", ErrInvalidConfig)
+		}
+
+		opts.readYourWrites = window
+
+		return nil
+	})
+}
+
+func (hp *HealthCheckPolicy) validate() error {
+	if hp.Interval <= 0 {
+		return fmt.Errorf("%w: HealthCheckPolicy: Interval must be greater than zero", ErrInvalidConfig)
+	}
+
+	if hp.Timeout <= 0 {
+		return fmt.Errorf("%w: HealthCheckPolicy: Timeout must be greater than zero", ErrInvalidConfig)
+	}
+
+	if hp.DegradedLatency < 0 {
+		return fmt.Errorf("%w: HealthCheckPolicy: DegradedLatency must not be negative", ErrInvalidConfig)
+	}
+
+	if hp.FailureThreshold <= 0 {
+		return fmt.Errorf("%w: HealthCheckPolicy: FailureThreshold must be greater than zero", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// WithHealthCheck enables background health checks of database connection.
+// Result is available with DB.Status, DB.ReadinessHandler and DB.LivenessHandler.
+func WithHealthCheck(healthCheckPolicy HealthCheckPolicy) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if err := healthCheckPolicy.validate(); err != nil {
+			return err
+		}
+
+		opts.healthCheck = &healthCheckPolicy
+
+		return nil
+	})
+}
+
+// StartupPolicy defines how the initial connection is established by New.
+//
+// By default New pings the database once and fails if it is unavailable.
+type StartupPolicy struct {
+	// Lazy makes New return without connecting to the database.
+	// Connections are established on first use. Instance is
+	// discovered on reconnect only in lazy mode, see DB.Instance.
+	Lazy bool
+
+	// Retry retries the initial connect with backoff. If ErrIsRetryable
+	// is empty, all errors are retried.
+	//
+	// This field can't be used with Lazy.
+	Retry *RetryPolicy
+
+	// Timeout limits the total time of the initial connect including retries.
+	// Zero means no limit besides the context passed to NewContext.
+	//
+	// This field must not be negative.
+	Timeout time.Duration
+}
+
+func (sp *StartupPolicy) validate() error {
+	if sp.Lazy && sp.Retry != nil {
+		return fmt.Errorf("%w: StartupPolicy: Lazy and Retry are mutually exclusive", ErrInvalidConfig)
+	}
+
+	if sp.Timeout < 0 {
+		return fmt.Errorf("%w: StartupPolicy: Timeout must not be negative", ErrInvalidConfig)
+	}
+
+	if sp.Retry == nil {
+		return nil
+	}
+
+	retryPolicy := *sp.Retry
+
+	if retryPolicy.ErrIsRetryable == nil {
+		retryPolicy.ErrIsRetryable = func(err error) bool { return true }
+	}
+
+	if err := retryPolicy.validate(); err != nil {
+		return fmt.Errorf("StartupPolicy: %w", err)
+	}
+
+	sp.Retry = &retryPolicy
+
+	return nil
+}
+
+// WithStartupPolicy sets how New establishes the initial connection.
+func WithStartupPolicy(policy StartupPolicy) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+
+		opts.startup = policy
+
+		return nil
+	})
+}
+
+// WithLazyConnect makes New return without connecting to the database.
+func WithLazyConnect() Option {
+	return WithStartupPolicy(StartupPolicy{Lazy: true})
+}
@@ -148,3 +148,166 @@ func TestWithMetricsHook(t *testing.T) {
 		})
 	}
 }
+
+func TestWithReconnectPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ReconnectPolicy
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "pass",
+			policy: ReconnectPolicy{
+				MinInterval:  DefaultReconnectMinInterval,
+				MaxInterval:  DefaultReconnectMaxInterval,
+				DrainTimeout: DefaultReconnectDrainTimeout,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid MinInterval",
+			policy: ReconnectPolicy{
+				MinInterval: 0,
+				MaxInterval: DefaultReconnectMaxInterval,
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid MaxInterval",
+			policy: ReconnectPolicy{
+				MinInterval: time.Minute,
+				MaxInterval: time.Second,
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid DrainTimeout",
+			policy: ReconnectPolicy{
+				MinInterval:  DefaultReconnectMinInterval,
+				MaxInterval:  DefaultReconnectMaxInterval,
+				DrainTimeout: -1,
+			},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts options
+
+			err := opts.apply(&hooks.Config{}, WithReconnectPolicy(tt.policy))
+
+			tt.wantErr(t, err, "validate()")
+		})
+	}
+}
+
+func TestWithHealthCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  HealthCheckPolicy
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "pass",
+			policy: HealthCheckPolicy{
+				Interval:         DefaultHealthCheckInterval,
+				Timeout:          DefaultHealthCheckTimeout,
+				FailureThreshold: DefaultHealthCheckFailureThreshold,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid Interval",
+			policy: HealthCheckPolicy{
+				Timeout:          DefaultHealthCheckTimeout,
+				FailureThreshold: DefaultHealthCheckFailureThreshold,
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid Timeout",
+			policy: HealthCheckPolicy{
+				Interval:         DefaultHealthCheckInterval,
+				FailureThreshold: DefaultHealthCheckFailureThreshold,
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid FailureThreshold",
+			policy: HealthCheckPolicy{
+				Interval: DefaultHealthCheckInterval,
+				Timeout:  DefaultHealthCheckTimeout,
+			},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts options
+
+			err := opts.apply(&hooks.Config{}, WithHealthCheck(tt.policy))
+
+			tt.wantErr(t, err, "validate()")
+		})
+	}
+}
+
+func TestWithStartupPolicy(t *testing.T) {
+	retry := &RetryPolicy{
+		MaxAttempts:       DefaultRetryAttempts,
+		InitialBackoff:    DefaultRetryInitialBackoff,
+		MaxBackoff:        DefaultRetryMaxBackoff,
+		BackoffMultiplier: DefaultRetryBackoffMultiplier,
+	}
+
+	tests := []struct {
+		name    string
+		policy  StartupPolicy
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "pass empty",
+			policy:  StartupPolicy{},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "pass lazy",
+			policy:  StartupPolicy{Lazy: true},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "pass retry without ErrIsRetryable",
+			policy:  StartupPolicy{Retry: retry, Timeout: time.Minute},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "lazy with retry",
+			policy:  StartupPolicy{Lazy: true, Retry: retry},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid retry",
+			policy:  StartupPolicy{Retry: &RetryPolicy{MaxAttempts: 1}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid Timeout",
+			policy:  StartupPolicy{Timeout: -1},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts options
+
+			err := opts.apply(&hooks.Config{}, WithStartupPolicy(tt.policy))
+
+			tt.wantErr(t, err, "validate()")
+
+			if err == nil && opts.startup.Retry != nil {
+				assert.NotNil(t, opts.startup.Retry.ErrIsRetryable)
+				assert.Nil(t, retry.ErrIsRetryable, "policy must not be mutated")
+			}
+		})
+	}
+}
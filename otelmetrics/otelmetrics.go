@@ -0,0 +1,276 @@
+// Package otelmetrics implements hooks.MetricCollector with OpenTelemetry
+// metrics following the database client semantic conventions.
+package otelmetrics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+)
+
+const _instrumentationName = "github.com/loghole/database"
+
+const (
+	_poolNameKey  = attribute.Key("pool.name")
+	_stateKey     = attribute.Key("state")
+	_errorTypeKey = attribute.Key("error.type")
+
+	_stateIdle = "idle"
+	_stateUsed = "used"
+
+	_errorTypeOther = "_OTHER"
+)
+
+// Collector collects metrics of queries and connection pool:
+//
+//	db.client.operation.duration            histogram of query duration in seconds
+//	db.client.serialization_failures        counter of transaction serialization failures
+//	db.client.connections.usage             connections by state, idle or used
+//	db.client.connections.max               maximum number of open connections
+//	db.client.connections.pending_requests  requests waiting for a connection
+//
+// Connection metrics are collected when Collector is passed to WithPoolStats.
+// Statistics of the previous address are deleted after reconnect to other
+// node and on Close of the DB. The SDK keeps exporting the last observed
+// values, so connections of deleted pools are observed as zero once.
+type Collector struct {
+	operationDuration     metric.Float64Histogram
+	serializationFailures metric.Int64Counter
+
+	usage   metric.Int64ObservableUpDownCounter
+	max     metric.Int64ObservableUpDownCounter
+	pending metric.Int64ObservableUpDownCounter
+
+	poolName string
+
+	mu           sync.Mutex
+	stats        map[string]sql.DBStats
+	pendingStats map[string]int
+	deleted      map[string]bool // pending requests were observed if true.
+}
+
+// Option configures Collector.
+type Option func(c *Collector)
+
+// WithPoolName sets pool.name attribute of connection metrics. By default
+// it is address and name of the database, so databases with the same
+// address and name must use collectors with distinct pool names.
+func WithPoolName(name string) Option {
+	return func(c *Collector) {
+		c.poolName = name
+	}
+}
+
+// New creates collector with meter of provider,
+// otel.GetMeterProvider is used if provider is nil.
+func New(provider metric.MeterProvider, opts ...Option) (*Collector, error) {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+
+	var (
+		meter = provider.Meter(_instrumentationName)
+		c     = &Collector{
+			stats:        make(map[string]sql.DBStats),
+			pendingStats: make(map[string]int),
+			deleted:      make(map[string]bool),
+		}
+		err error
+	)
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.operationDuration, err = meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of database client operations"),
+	); err != nil {
+		return nil, fmt.Errorf("create 'db.client.operation.duration' histogram: %w", err)
+	}
+
+	if c.serializationFailures, err = meter.Int64Counter(
+		"db.client.serialization_failures",
+		metric.WithUnit("{failure}"),
+		metric.WithDescription("The number of transaction serialization failures"),
+	); err != nil {
+		return nil, fmt.Errorf("create 'db.client.serialization_failures' counter: %w", err)
+	}
+
+	if c.usage, err = meter.Int64ObservableUpDownCounter(
+		"db.client.connections.usage",
+		metric.WithUnit("{connection}"),
+		metric.WithDescription("The number of connections that are currently in state described by the state attribute"),
+	); err != nil {
+		return nil, fmt.Errorf("create 'db.client.connections.usage' counter: %w", err)
+	}
+
+	if c.max, err = meter.Int64ObservableUpDownCounter(
+		"db.client.connections.max",
+		metric.WithUnit("{connection}"),
+		metric.WithDescription("The maximum number of open connections allowed"),
+	); err != nil {
+		return nil, fmt.Errorf("create 'db.client.connections.max' counter: %w", err)
+	}
+
+	if c.pending, err = meter.Int64ObservableUpDownCounter(
+		"db.client.connections.pending_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("The number of pending requests for an open connection"),
+	); err != nil {
+		return nil, fmt.Errorf("create 'db.client.connections.pending_requests' counter: %w", err)
+	}
+
+	if _, err := meter.RegisterCallback(c.observe, c.usage, c.max, c.pending); err != nil {
+		return nil, fmt.Errorf("register callback: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *Collector) SerializationFailureInc(dbType, dbAddr, dbName string) {
+	c.serializationFailures.Add(context.Background(), 1, metric.WithAttributes(serverAttrs(dbType, dbAddr, dbName)...))
+}
+
+func (c *Collector) QueryDurationObserve(
+	dbType, dbAddr, dbName, operation, table string,
+	isError bool,
+	since time.Duration,
+) {
+	attrs := append(serverAttrs(dbType, dbAddr, dbName), semconv.DBOperationKey.String(operation))
+
+	if table != "" {
+		attrs = append(attrs, semconv.DBSQLTableKey.String(table))
+	}
+
+	if isError {
+		attrs = append(attrs, _errorTypeKey.String(_errorTypeOther))
+	}
+
+	c.operationDuration.Record(context.Background(), since.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// PoolStatsObserve records statistics of connection pool, see hooks.PoolStatsCollector.
+func (c *Collector) PoolStatsObserve(dbType, dbAddr, dbName string, stats sql.DBStats) { //nolint:gocritic // interface.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats[c.pool(dbAddr, dbName)] = stats
+	delete(c.deleted, c.pool(dbAddr, dbName))
+}
+
+// PendingRequestsObserve records the number of requests waiting for a connection,
+// see hooks.PendingRequestsCollector.
+func (c *Collector) PendingRequestsObserve(dbType, dbAddr, dbName string, pending int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pendingStats[c.pool(dbAddr, dbName)] = pending
+	delete(c.deleted, c.pool(dbAddr, dbName))
+}
+
+// PoolStatsDelete deletes statistics of connection pool, see hooks.PoolStatsDeleter.
+func (c *Collector) PoolStatsDelete(dbType, dbAddr, dbName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pool := c.pool(dbAddr, dbName)
+
+	if _, ok := c.stats[pool]; !ok {
+		return
+	}
+
+	_, pending := c.pendingStats[pool]
+
+	delete(c.stats, pool)
+	delete(c.pendingStats, pool)
+
+	c.deleted[pool] = pending
+}
+
+func (c *Collector) observe(_ context.Context, observer metric.Observer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, stats := range c.stats {
+		pool := _poolNameKey.String(name)
+
+		observer.ObserveInt64(c.usage, int64(stats.Idle), metric.WithAttributes(pool, _stateKey.String(_stateIdle)))
+		observer.ObserveInt64(c.usage, int64(stats.InUse), metric.WithAttributes(pool, _stateKey.String(_stateUsed)))
+		observer.ObserveInt64(c.max, int64(stats.MaxOpenConnections), metric.WithAttributes(pool))
+	}
+
+	for name, pending := range c.pendingStats {
+		observer.ObserveInt64(c.pending, int64(pending), metric.WithAttributes(_poolNameKey.String(name)))
+	}
+
+	for name, pending := range c.deleted {
+		pool := _poolNameKey.String(name)
+
+		observer.ObserveInt64(c.usage, 0, metric.WithAttributes(pool, _stateKey.String(_stateIdle)))
+		observer.ObserveInt64(c.usage, 0, metric.WithAttributes(pool, _stateKey.String(_stateUsed)))
+		observer.ObserveInt64(c.max, 0, metric.WithAttributes(pool))
+
+		if pending {
+			observer.ObserveInt64(c.pending, 0, metric.WithAttributes(pool))
+		}
+
+		delete(c.deleted, name)
+	}
+
+	return nil
+}
+
+// pool returns name of connection pool, see WithPoolName.
+func (c *Collector) pool(dbAddr, dbName string) string {
+	if c.poolName != "" {
+		return c.poolName
+	}
+
+	return dbAddr + "/" + dbName
+}
+
+// serverAttrs returns attributes of database server.
+func serverAttrs(dbType, dbAddr, dbName string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{dbSystem(dbType), semconv.DBNameKey.String(dbName)}
+
+	host, port, err := net.SplitHostPort(dbAddr)
+	if err != nil {
+		return append(attrs, semconv.NetPeerNameKey.String(dbAddr))
+	}
+
+	attrs = append(attrs, semconv.NetPeerNameKey.String(host))
+
+	if port, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.NetPeerPortKey.Int(port))
+	}
+
+	return attrs
+}
+
+// dbSystem returns db.system attribute of the database type.
+// Cockroach is reported as postgres type by hooks.Config.
+func dbSystem(dbType string) attribute.KeyValue {
+	switch dbType {
+	case "postgres", "pgx":
+		return semconv.DBSystemPostgreSQL
+	case "clickhouse":
+		return semconv.DBSystemClickhouse
+	case "sqlite3":
+		return semconv.DBSystemSqlite
+	case "mysql":
+		return semconv.DBSystemMySQL
+	default:
+		return semconv.DBSystemOtherSQL
+	}
+}
@@ -0,0 +1,191 @@
+package otelmetrics
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+)
+
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	metrics := make(map[string]metricdata.Aggregation)
+
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	return metrics
+}
+
+func TestCollector_QueryDurationObserve(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+
+	c, err := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	require.NoError(t, err)
+
+	c.QueryDurationObserve("pgx", "localhost:5432", "db", "select", "users", false, 2*time.Second)
+	c.QueryDurationObserve("pgx", "localhost:5432", "db", "select", "users", true, time.Second)
+	c.SerializationFailureInc("postgres", "local", "db")
+
+	metrics := collect(t, reader)
+
+	histogram, ok := metrics["db.client.operation.duration"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, histogram.DataPoints, 2)
+
+	for _, point := range histogram.DataPoints {
+		assert.Equal(t, uint64(1), point.Count)
+
+		for _, attr := range []attribute.KeyValue{
+			semconv.DBSystemPostgreSQL,
+			semconv.DBNameKey.String("db"),
+			semconv.DBOperationKey.String("select"),
+			semconv.DBSQLTableKey.String("users"),
+			semconv.NetPeerNameKey.String("localhost"),
+			semconv.NetPeerPortKey.Int(5432),
+		} {
+			val, ok := point.Attributes.Value(attr.Key)
+			assert.True(t, ok, attr.Key)
+			assert.Equal(t, attr.Value, val, attr.Key)
+		}
+
+		errType, failed := point.Attributes.Value(_errorTypeKey)
+
+		if point.Sum == 1 {
+			assert.True(t, failed)
+			assert.Equal(t, _errorTypeOther, errType.AsString())
+		} else {
+			assert.False(t, failed)
+		}
+	}
+
+	counter, ok := metrics["db.client.serialization_failures"].(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, counter.DataPoints, 1)
+	assert.Equal(t, int64(1), counter.DataPoints[0].Value)
+
+	system, _ := counter.DataPoints[0].Attributes.Value(semconv.DBSystemKey)
+	assert.Equal(t, "postgresql", system.AsString())
+
+	host, _ := counter.DataPoints[0].Attributes.Value(semconv.NetPeerNameKey)
+	assert.Equal(t, "local", host.AsString())
+}
+
+func TestCollector_PoolStatsObserve(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+
+	c, err := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	require.NoError(t, err)
+
+	c.PoolStatsObserve("pgx", "localhost:5432", "db", sql.DBStats{MaxOpenConnections: 10, InUse: 3, Idle: 2})
+	c.PendingRequestsObserve("pgx", "localhost:5432", "db", 4)
+
+	metrics := collect(t, reader)
+
+	values := func(name string) map[string]int64 {
+		sum, ok := metrics[name].(metricdata.Sum[int64])
+		require.True(t, ok, name)
+		assert.False(t, sum.IsMonotonic, name)
+
+		values := make(map[string]int64)
+
+		for _, point := range sum.DataPoints {
+			pool, _ := point.Attributes.Value(_poolNameKey)
+			state, _ := point.Attributes.Value(_stateKey)
+
+			values[pool.AsString()+" "+state.AsString()] = point.Value
+		}
+
+		return values
+	}
+
+	assert.Equal(t, map[string]int64{
+		"localhost:5432/db idle": 2,
+		"localhost:5432/db used": 3,
+	}, values("db.client.connections.usage"))
+	assert.Equal(t, map[string]int64{"localhost:5432/db ": 10}, values("db.client.connections.max"))
+	assert.Equal(t, map[string]int64{"localhost:5432/db ": 4}, values("db.client.connections.pending_requests"))
+}
+
+func TestCollector_PoolStatsDelete(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+
+	c, err := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	require.NoError(t, err)
+
+	c.PoolStatsObserve("pgx", "first:5432", "db", sql.DBStats{MaxOpenConnections: 10})
+	c.PendingRequestsObserve("pgx", "first:5432", "db", 1)
+	c.PoolStatsObserve("pgx", "second:5432", "db", sql.DBStats{MaxOpenConnections: 20})
+	c.PendingRequestsObserve("pgx", "second:5432", "db", 2)
+
+	collect(t, reader)
+
+	c.PoolStatsDelete("pgx", "first:5432", "db")
+
+	metrics := collect(t, reader)
+
+	values := func(name string) map[string]int64 {
+		sum, ok := metrics[name].(metricdata.Sum[int64])
+		require.True(t, ok, name)
+
+		values := make(map[string]int64)
+
+		for _, point := range sum.DataPoints {
+			pool, _ := point.Attributes.Value(_poolNameKey)
+
+			values[pool.AsString()] = point.Value
+		}
+
+		return values
+	}
+
+	assert.Equal(t, map[string]int64{"first:5432/db": 0, "second:5432/db": 20}, values("db.client.connections.max"))
+	assert.Equal(t, map[string]int64{"first:5432/db": 0, "second:5432/db": 2},
+		values("db.client.connections.pending_requests"))
+
+	c.mu.Lock()
+	assert.Empty(t, c.deleted, "must be evicted after zero is observed")
+	c.mu.Unlock()
+}
+
+func TestCollector_WithPoolName(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	first, err := New(provider, WithPoolName("first"))
+	require.NoError(t, err)
+
+	second, err := New(provider, WithPoolName("second"))
+	require.NoError(t, err)
+
+	first.PoolStatsObserve("pgx", "localhost:5432", "db", sql.DBStats{MaxOpenConnections: 10})
+	second.PoolStatsObserve("pgx", "localhost:5432", "db", sql.DBStats{MaxOpenConnections: 20})
+
+	sum, ok := collect(t, reader)["db.client.connections.max"].(metricdata.Sum[int64])
+	require.True(t, ok)
+
+	values := make(map[string]int64)
+
+	for _, point := range sum.DataPoints {
+		pool, _ := point.Attributes.Value(_poolNameKey)
+
+		values[pool.AsString()] = point.Value
+	}
+
+	assert.Equal(t, map[string]int64{"first": 10, "second": 20}, values)
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"github.com/loghole/dbhook"
+)
+
+type pendingContextKey struct{}
+
+// pendingRequest counts operation attempt as pending in the pool it is
+// routed to until the attempt gets a connection.
+type pendingRequest struct {
+	mu   sync.Mutex
+	pool *connPool
+}
+
+// route moves request to the pool. Nil pool means that request
+// got a connection or is finished.
+func (r *pendingRequest) route(pool *connPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pool != nil {
+		r.pool.pending.Add(-1)
+	}
+
+	if r.pool = pool; pool != nil {
+		pool.pending.Add(1)
+	}
+}
+
+// waitConn returns context of operation attempt which is pending in the pool
+// until hooks are called with a connection. The returned function must be
+// called when the attempt is finished.
+func (p *connPool) waitConn(ctx context.Context) (context.Context, func()) {
+	if !p.trackPending {
+		return ctx, func() {}
+	}
+
+	req := &pendingRequest{}
+	req.route(p)
+
+	return context.WithValue(ctx, pendingContextKey{}, req), func() { req.route(nil) }
+}
+
+// pendingRequests returns the number of operations waiting for a connection.
+func (p *connPool) pendingRequests() int {
+	return int(p.pending.Load())
+}
+
+// routePending moves pending request of the context to the pool.
+func routePending(ctx context.Context, pool *connPool) {
+	if req, ok := ctx.Value(pendingContextKey{}).(*pendingRequest); ok {
+		req.route(pool)
+	}
+}
+
+// pendingHook marks pending request of the context as got a connection,
+// hooks are called by the driver with an acquired connection.
+type pendingHook struct{}
+
+func (pendingHook) Before(ctx context.Context, input *dbhook.HookInput) (context.Context, error) {
+	routePending(ctx, nil)
+
+	return ctx, input.Error
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pendingRecorder struct {
+	poolStatsRecorder
+
+	pendingMu sync.Mutex
+	pending   []int
+}
+
+func (r *pendingRecorder) PendingRequestsObserve(dbType, dbAddr, dbName string, pending int) {
+	r.pendingMu.Lock()
+	defer r.pendingMu.Unlock()
+
+	r.pending = append(r.pending, pending)
+}
+
+func TestDB_pendingRequests(t *testing.T) {
+	ctx := context.Background()
+	recorder := &pendingRecorder{}
+
+	db, err := New(&Config{Database: ":memory:", Type: SQLiteDatabase, MaxOpenConns: 1},
+		WithPoolStats(recorder, time.Hour))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, db.pool.pendingRequests(), "request with connection must not be pending")
+
+	done := make(chan error)
+
+	go func() {
+		_, err := db.ExecContext(ctx, "SELECT 1")
+		done <- err
+	}()
+
+	assert.Eventually(t, func() bool { return db.pool.pendingRequests() == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, tx.Rollback())
+	require.NoError(t, <-done)
+
+	assert.Equal(t, 0, db.pool.pendingRequests())
+
+	recorder.pendingMu.Lock()
+	defer recorder.pendingMu.Unlock()
+
+	assert.Equal(t, []int{0}, recorder.pending, "pending requests must be exported with pool stats")
+}
+
+func TestDB_pendingRequests_disabled(t *testing.T) {
+	db, err := New(&Config{Database: ":memory:", Type: SQLiteDatabase},
+		WithPoolStats(&poolStatsRecorder{}, time.Hour))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	assert.False(t, db.pool.trackPending)
+	assert.Nil(t, db.hook, "hooks must not be added without pending requests collector")
+}
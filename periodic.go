@@ -0,0 +1,45 @@
+package database
+
+import (
+	"sync"
+	"time"
+)
+
+// periodic runs fn every interval in background until it is closed.
+type periodic struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func startPeriodic(interval time.Duration, fn func()) *periodic {
+	p := &periodic{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go p.run(interval, fn)
+
+	return p
+}
+
+func (p *periodic) run(interval time.Duration, fn func()) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fn()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *periodic) close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+	<-p.done
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/loghole/dbhook"
+
+	"github.com/loghole/database/hooks"
+)
+
+type pgxPoolOptions struct {
+	configure []func(cfg *pgxpool.Config)
+}
+
+// WithPGXPool backs PGXDatabase connections by pgxpool.Pool. Queries still
+// pass through hooks and retry policy, SendBatch and CopyFrom use the pool.
+//
+// Config.MaxOpenConns, ConnMaxLifetime and ConnMaxIdleTime are applied to
+// the pool, idle connections are kept by the pool only, so MaxIdleConns
+// is ignored. Configure funcs run last and may override any pool setting.
+func WithPGXPool(configure ...func(cfg *pgxpool.Config)) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if cfg.Type != PGXDatabase.String() {
+			return fmt.Errorf("%w: pgx pool requires %s database type", ErrInvalidConfig, PGXDatabase)
+		}
+
+		for _, fn := range configure {
+			if fn == nil {
+				return fmt.Errorf("%w: pgx pool configure func must be non-empty", ErrInvalidConfig)
+			}
+		}
+
+		opts.pgxPool = &pgxPoolOptions{configure: configure}
+
+		return nil
+	})
+}
+
+// pgxPoolConnector creates pgxpool.Pool for dsn and returns connector
+// which acquires connections from it. The pool is closed with connector.
+func (db *DB) pgxPoolConnector(dsn string) (driver.Connector, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse pgx pool config: %w", err)
+	}
+
+	if tlsCfg := db.baseCfg.tlsConfig(); tlsCfg != nil {
+		tlsCfg.applyPGX(poolConfig.ConnConfig)
+	}
+
+	if db.baseCfg.MaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(db.baseCfg.MaxOpenConns)
+	}
+
+	if db.baseCfg.ConnMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = db.baseCfg.ConnMaxLifetime
+	}
+
+	if db.baseCfg.ConnMaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = db.baseCfg.ConnMaxIdleTime
+	}
+
+	if provider := db.baseCfg.Credentials; provider != nil {
+		poolConfig.BeforeConnect = func(ctx context.Context, connConfig *pgx.ConnConfig) error {
+			creds, err := provider.Credentials(ctx)
+			if err != nil {
+				return fmt.Errorf("get credentials: %w", err)
+			}
+
+			if creds.User != "" {
+				connConfig.User = creds.User
+			}
+
+			connConfig.Password = creds.Password
+
+			return nil
+		}
+	}
+
+	if onConnect := db.onConnect(); len(onConnect) > 0 {
+		poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			session := &pgxSession{conn: conn, hook: db.hook}
+
+			for _, fn := range onConnect {
+				if err := fn(ctx, session); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	for _, fn := range db.options.pgxPool.configure {
+		fn(poolConfig)
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("new pgx pool: %w", err)
+	}
+
+	return &pgxPoolConnector{Connector: stdlib.GetPoolConnector(pool), pool: pool}, nil
+}
+
+// pgxPoolConnector closes pgxpool.Pool when database/sql pool is closed.
+type pgxPoolConnector struct {
+	driver.Connector
+	pool *pgxpool.Pool
+}
+
+func (c *pgxPoolConnector) Close() error {
+	c.pool.Close()
+
+	return nil
+}
+
+// pgxSession is a new pool connection passed to OnConnectFunc.
+// Its queries pass through hooks like queries of database/sql connections.
+type pgxSession struct {
+	conn *pgx.Conn
+	hook dbhook.Hook
+}
+
+func (s *pgxSession) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+
+	input := &dbhook.HookInput{Query: query, Args: hookArgs(args), Caller: dbhook.CallerExec}
+
+	err := runHook(ctx, s.hook, input, func(ctx context.Context) error {
+		tag, err := s.conn.Exec(ctx, query, args...)
+		if err != nil {
+			return err //nolint:wrapcheck // need clean err.
+		}
+
+		result = driver.RowsAffected(tag.RowsAffected())
+
+		return nil
+	})
+
+	return result, err
+}
+
+// runHook runs fn between hook calls the same way as dbhook does for
+// database/sql connections.
+func runHook(ctx context.Context, hook dbhook.Hook, input *dbhook.HookInput, fn func(ctx context.Context) error) error {
+	if hook == nil {
+		return fn(ctx)
+	}
+
+	ctx, err := hook.Before(ctx, input)
+	if err != nil {
+		return err //nolint:wrapcheck // need clean err.
+	}
+
+	if input.Error = fn(ctx); input.Error != nil {
+		if _, err := hook.Error(ctx, input); err != nil {
+			return err //nolint:wrapcheck // need clean err.
+		}
+	}
+
+	if _, err := hook.After(ctx, input); err != nil {
+		return err //nolint:wrapcheck // need clean err.
+	}
+
+	return nil
+}
+
+func hookArgs(args []interface{}) []driver.Value {
+	values := make([]driver.Value, len(args))
+
+	for i, arg := range args {
+		values[i] = arg
+	}
+
+	return values
+}
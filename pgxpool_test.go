@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/loghole/dbhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPGXPool(t *testing.T) {
+	tests := []struct {
+		name      string
+		dbType    DBType
+		configure []func(cfg *pgxpool.Config)
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "pass",
+			dbType:  PGXDatabase,
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "pass with configure",
+			dbType:    PGXDatabase,
+			configure: []func(cfg *pgxpool.Config){func(cfg *pgxpool.Config) {}},
+			wantErr:   assert.NoError,
+		},
+		{
+			name:    "postgres",
+			dbType:  PostgresDatabase,
+			wantErr: assert.Error,
+		},
+		{
+			name:      "nil configure",
+			dbType:    PGXDatabase,
+			configure: []func(cfg *pgxpool.Config){nil},
+			wantErr:   assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Addr: "127.0.0.1:5432", User: "postgres", Database: "db", Type: tt.dbType}
+
+			opts := defaultOptions()
+
+			err := opts.apply(cfg.hookConfig(), WithPGXPool(tt.configure...))
+			tt.wantErr(t, err)
+
+			if err != nil {
+				assert.ErrorIs(t, err, ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestNew_pgxPool(t *testing.T) {
+	var poolConfig *pgxpool.Config
+
+	db, err := New(&Config{
+		Addr:            "127.0.0.1:5432",
+		User:            "postgres:secret",
+		Database:        "database",
+		Type:            PGXDatabase,
+		MaxOpenConns:    7,
+		MaxIdleConns:    3,
+		ConnMaxLifetime: time.Minute,
+	}, WithLazyConnect(), WithOnConnect(func(ctx context.Context, conn SessionConn) error {
+		return nil
+	}), WithPGXPool(func(cfg *pgxpool.Config) {
+		poolConfig = cfg
+	}))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	require.NotNil(t, poolConfig)
+	assert.Equal(t, int32(7), poolConfig.MaxConns)
+	assert.Equal(t, time.Minute, poolConfig.MaxConnLifetime)
+	assert.Equal(t, "postgres", poolConfig.ConnConfig.User)
+	assert.Equal(t, "secret", poolConfig.ConnConfig.Password)
+	assert.NotNil(t, poolConfig.AfterConnect)
+	assert.Nil(t, poolConfig.BeforeConnect)
+
+	db.SetMaxIdleConns(5)
+
+	assert.Equal(t, 0, db.SQLx().Stats().Idle)
+	assert.Equal(t, "$1", db.SQLx().Rebind("?"))
+}
+
+type recordHook struct {
+	calls   []string
+	swallow bool
+}
+
+func (h *recordHook) Before(ctx context.Context, _ *dbhook.HookInput) (context.Context, error) {
+	h.calls = append(h.calls, "before")
+
+	return ctx, nil
+}
+
+func (h *recordHook) After(ctx context.Context, _ *dbhook.HookInput) (context.Context, error) {
+	h.calls = append(h.calls, "after")
+
+	return ctx, nil
+}
+
+func (h *recordHook) Error(ctx context.Context, input *dbhook.HookInput) (context.Context, error) {
+	h.calls = append(h.calls, "error")
+
+	if h.swallow {
+		return ctx, nil
+	}
+
+	return ctx, input.Error
+}
+
+func Test_runHook(t *testing.T) {
+	errQuery := errors.New("query failed")
+
+	tests := []struct {
+		name      string
+		swallow   bool
+		err       error
+		wantCalls []string
+		wantErr   error
+	}{
+		{
+			name:      "pass",
+			wantCalls: []string{"before", "after"},
+		},
+		{
+			name:      "error",
+			err:       errQuery,
+			wantCalls: []string{"before", "error"},
+			wantErr:   errQuery,
+		},
+		{
+			name:      "swallowed error",
+			swallow:   true,
+			err:       errQuery,
+			wantCalls: []string{"before", "error", "after"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				hook  = &recordHook{swallow: tt.swallow}
+				input = &dbhook.HookInput{Query: "SELECT 1"}
+			)
+
+			err := runHook(context.Background(), hook, input, func(ctx context.Context) error {
+				return tt.err
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantCalls, hook.calls)
+		})
+	}
+}
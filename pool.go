@@ -0,0 +1,174 @@
+package database
+
+import (
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// connPool holds current connection pool of DB and its settings.
+// Settings are recorded so that they can be re-applied to every
+// new pool created on reconnect.
+type connPool struct {
+	current atomic.Pointer[sqlx.DB]
+
+	mu sync.Mutex
+
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+	connMaxIdleTime time.Duration
+
+	// Zero MaxIdleConns disables idle connections in database/sql,
+	// so it is applied only when it was set explicitly.
+	maxIdleConnsSet bool
+
+	// Idle connections of pgx pool mode are kept by pgxpool,
+	// so database/sql releases connections after every use.
+	externalIdle bool
+
+	// Operations waiting for a connection, they are counted only if
+	// pool stats collector needs them.
+	trackPending bool
+	pending      atomic.Int64
+
+	// Pools replaced on reconnect, their statistics are added to Stats.
+	statsMu  sync.Mutex
+	draining map[*sqlx.DB]struct{}
+	retired  sql.DBStats
+}
+
+func newConnPool(cfg *Config) *connPool {
+	return &connPool{
+		maxOpenConns:    cfg.MaxOpenConns,
+		maxIdleConns:    cfg.MaxIdleConns,
+		connMaxLifetime: cfg.ConnMaxLifetime,
+		connMaxIdleTime: cfg.ConnMaxIdleTime,
+		maxIdleConnsSet: cfg.MaxIdleConns != 0,
+	}
+}
+
+// load returns current pool.
+func (p *connPool) load() *sqlx.DB {
+	return p.current.Load()
+}
+
+// replace applies recorded settings to the db and atomically
+// sets it as current pool. It returns previous pool or nil.
+func (p *connPool) replace(db *sqlx.DB) *sqlx.DB {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// MaxOpenConns must be applied before MaxIdleConns
+	// because it may reduce MaxIdleConns.
+	db.SetMaxOpenConns(p.maxOpenConns)
+
+	switch {
+	case p.externalIdle:
+		db.SetMaxIdleConns(0)
+	case p.maxIdleConnsSet:
+		db.SetMaxIdleConns(p.maxIdleConns)
+	}
+
+	db.SetConnMaxLifetime(p.connMaxLifetime)
+	db.SetConnMaxIdleTime(p.connMaxIdleTime)
+
+	old := p.current.Swap(db)
+
+	if old != nil {
+		p.statsMu.Lock()
+		defer p.statsMu.Unlock()
+
+		if p.draining == nil {
+			p.draining = make(map[*sqlx.DB]struct{})
+		}
+
+		p.draining[old] = struct{}{}
+	}
+
+	return old
+}
+
+// retire records statistics of the closed pool returned by replace.
+func (p *connPool) retire(db *sqlx.DB) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	delete(p.draining, db)
+	addCounters(&p.retired, db.Stats())
+}
+
+// stats returns statistics of the current pool. Counters include
+// pools replaced on reconnect, so they don't reset.
+func (p *connPool) stats() sql.DBStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	stats := p.load().Stats()
+
+	// Connections of draining pools are still open.
+	for db := range p.draining {
+		draining := db.Stats()
+
+		stats.OpenConnections += draining.OpenConnections
+		stats.InUse += draining.InUse
+		stats.Idle += draining.Idle
+
+		addCounters(&stats, draining)
+	}
+
+	addCounters(&stats, p.retired)
+
+	return stats
+}
+
+// addCounters adds cumulative counters of src to dst.
+func addCounters(dst *sql.DBStats, src sql.DBStats) { //nolint:gocritic // sql.DBStats is passed by value in database/sql.
+	dst.WaitCount += src.WaitCount
+	dst.WaitDuration += src.WaitDuration
+	dst.MaxIdleClosed += src.MaxIdleClosed
+	dst.MaxIdleTimeClosed += src.MaxIdleTimeClosed
+	dst.MaxLifetimeClosed += src.MaxLifetimeClosed
+}
+
+func (p *connPool) setMaxOpenConns(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.maxOpenConns = n
+
+	p.load().SetMaxOpenConns(n)
+}
+
+func (p *connPool) setMaxIdleConns(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.maxIdleConns = n
+	p.maxIdleConnsSet = true
+
+	if !p.externalIdle {
+		p.load().SetMaxIdleConns(n)
+	}
+}
+
+func (p *connPool) setConnMaxLifetime(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.connMaxLifetime = d
+
+	p.load().SetConnMaxLifetime(d)
+}
+
+func (p *connPool) setConnMaxIdleTime(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.connMaxIdleTime = d
+
+	p.load().SetConnMaxIdleTime(d)
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/loghole/database/internal/metrics"
+)
+
+// PrometheusOption configures metrics of WithPrometheusMetrics and
+// WithPrometheusPoolStats. Databases with the same options share metrics,
+// so several of them can be used in one process.
+type PrometheusOption func(opts *metrics.Options)
+
+// DefaultPrometheusBuckets are histogram buckets of query duration in milliseconds.
+//
+//nolint:gochecknoglobals // constant list.
+var DefaultPrometheusBuckets = metrics.DefaultBuckets
+
+// PrometheusRegisterer registers metrics in reg instead of prometheus.DefaultRegisterer.
+func PrometheusRegisterer(reg prometheus.Registerer) PrometheusOption {
+	return func(opts *metrics.Options) {
+		opts.Registerer = reg
+	}
+}
+
+// PrometheusNamespace sets namespace and subsystem of metric names.
+func PrometheusNamespace(namespace, subsystem string) PrometheusOption {
+	return func(opts *metrics.Options) {
+		opts.Namespace = namespace
+		opts.Subsystem = subsystem
+	}
+}
+
+// PrometheusConstLabels adds labels to all metrics, e.g. service name or
+// database alias to tell apart databases with the same address.
+func PrometheusConstLabels(labels prometheus.Labels) PrometheusOption {
+	return func(opts *metrics.Options) {
+		opts.ConstLabels = labels
+	}
+}
+
+// PrometheusHistogram observes query duration with histogram instead of
+// summary, so it can be aggregated across instances. Buckets are in
+// milliseconds, DefaultPrometheusBuckets are used if they are empty.
+func PrometheusHistogram(buckets ...float64) PrometheusOption {
+	return func(opts *metrics.Options) {
+		opts.Buckets = buckets
+
+		if len(buckets) == 0 {
+			opts.Buckets = DefaultPrometheusBuckets
+		}
+	}
+}
+
+// PrometheusNativeHistogram observes query duration with native histogram,
+// see prometheus.HistogramOpts.NativeHistogramBucketFactor.
+func PrometheusNativeHistogram(bucketFactor float64) PrometheusOption {
+	return func(opts *metrics.Options) {
+		opts.NativeHistogramBucketFactor = bucketFactor
+	}
+}
+
+// newPrometheusMetrics returns metrics registered with options.
+// Metrics without options are registered once in the default registerer.
+func newPrometheusMetrics(opts []PrometheusOption) (*metrics.Metrics, error) {
+	if len(opts) == 0 {
+		return metrics.NewMetrics() //nolint:wrapcheck // wrapped by caller.
+	}
+
+	var metricsOpts metrics.Options
+
+	for _, opt := range opts {
+		opt(&metricsOpts)
+	}
+
+	if metricsOpts.NativeHistogramBucketFactor != 0 && metricsOpts.NativeHistogramBucketFactor <= 1 {
+		return nil, fmt.Errorf("%w: native histogram bucket factor must be greater than one", ErrInvalidConfig)
+	}
+
+	if !sort.Float64sAreSorted(metricsOpts.Buckets) {
+		return nil, fmt.Errorf("%w: histogram buckets must be sorted", ErrInvalidConfig)
+	}
+
+	return metrics.New(&metricsOpts) //nolint:wrapcheck // wrapped by caller.
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loghole/database/hooks"
+)
+
+func TestWithPrometheusMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []PrometheusOption
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "default",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "histogram",
+			opts: []PrometheusOption{
+				PrometheusRegisterer(prometheus.NewRegistry()),
+				PrometheusNamespace("app", "db"),
+				PrometheusConstLabels(prometheus.Labels{"service": "test"}),
+				PrometheusHistogram(),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "native histogram",
+			opts: []PrometheusOption{
+				PrometheusRegisterer(prometheus.NewRegistry()),
+				PrometheusNativeHistogram(1.1),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "unsorted buckets",
+			opts: []PrometheusOption{
+				PrometheusRegisterer(prometheus.NewRegistry()),
+				PrometheusHistogram(10, 1),
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid bucket factor",
+			opts: []PrometheusOption{
+				PrometheusRegisterer(prometheus.NewRegistry()),
+				PrometheusNativeHistogram(0.5),
+			},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts options
+
+			err := opts.apply(&hooks.Config{}, WithPrometheusMetrics(tt.opts...))
+
+			tt.wantErr(t, err, "validate()")
+		})
+	}
+}
+
+func TestWithPrometheusMetrics_failedQuery(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	for _, service := range []string{"first", "second"} {
+		db, err := New(&Config{Addr: "local", Database: ":memory:", Type: SQLiteDatabase},
+			WithPrometheusMetrics(
+				PrometheusRegisterer(reg),
+				PrometheusConstLabels(prometheus.Labels{"service": service}),
+			))
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(context.Background(), "SELECT * FROM unknown")
+		require.Error(t, err)
+
+		require.NoError(t, db.Close())
+	}
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	var failed []string
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["is_error"] == "true" {
+				failed = append(failed, labels["service"])
+			}
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"first", "second"}, failed, "failed query must be observed for every database")
+}
+
+func Test_newPrometheusMetrics_extended(t *testing.T) {
+	collector, err := newPrometheusMetrics([]PrometheusOption{PrometheusRegisterer(prometheus.NewRegistry())})
+	require.NoError(t, err)
+
+	assert.Implements(t, (*hooks.ExtendedMetricCollector)(nil), collector)
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/loghole/dbhook"
+
+	"github.com/loghole/database/internal/dbsqlx"
+)
+
+const _drainPollInterval = 100 * time.Millisecond
+
+// reconnector collapses concurrent reconnect calls into a single attempt
+// and enforces the minimum interval between attempts.
+type reconnector struct {
+	policy  ReconnectPolicy
+	connect func() error
+
+	mu       sync.Mutex
+	inflight *reconnectCall
+	lastAt   time.Time
+	lastErr  error
+	failures int
+}
+
+type reconnectCall struct {
+	done chan struct{}
+	err  error
+}
+
+func newReconnector(policy ReconnectPolicy, connect func() error) *reconnector {
+	return &reconnector{
+		policy:  policy,
+		connect: connect,
+	}
+}
+
+// reconnect runs connect func. If an attempt is already in flight, reconnect
+// waits for it and returns its result. If the previous attempt was finished
+// less than backoff ago, reconnect does nothing and returns nil for a
+// successful previous attempt or ErrReconnectThrottled otherwise.
+//
+// prepare is called only if the attempt runs, no other attempt can start
+// until it returns. If prepare returns false, the attempt is not needed
+// and reconnect returns nil.
+func (r *reconnector) reconnect(prepare func() bool) error {
+	r.mu.Lock()
+
+	if call := r.inflight; call != nil {
+		r.mu.Unlock()
+
+		<-call.done
+
+		return call.err
+	}
+
+	if !r.lastAt.IsZero() && time.Since(r.lastAt) < r.backoff() {
+		err := r.lastErr
+
+		r.mu.Unlock()
+
+		if err != nil {
+			return fmt.Errorf("%w: last attempt: %v", ErrReconnectThrottled, err) //nolint:errorlint // keep only one sentinel.
+		}
+
+		return nil
+	}
+
+	if prepare != nil && !prepare() {
+		r.mu.Unlock()
+
+		return nil
+	}
+
+	call := &reconnectCall{done: make(chan struct{})}
+	r.inflight = call
+
+	r.mu.Unlock()
+
+	call.err = r.connect()
+
+	r.mu.Lock()
+
+	r.inflight = nil
+	r.lastAt = time.Now()
+	r.lastErr = call.err
+
+	if call.err != nil {
+		r.failures++
+	} else {
+		r.failures = 0
+	}
+
+	r.mu.Unlock()
+
+	close(call.done)
+
+	return call.err
+}
+
+// backoff returns minimum interval since last attempt. The interval is
+// doubled on every failed attempt in a row up to MaxInterval.
+func (r *reconnector) backoff() time.Duration {
+	interval := r.policy.MinInterval
+
+	for i := 0; i < r.failures && interval < r.policy.MaxInterval; i++ {
+		interval *= 2
+	}
+
+	if interval > r.policy.MaxInterval {
+		interval = r.policy.MaxInterval
+	}
+
+	return interval
+}
+
+type poolNodeContextKey struct{}
+
+// poolNode is node of a connection pool. Every pool has its own poolNode,
+// so errors of a replaced pool are told apart from errors of the current one.
+type poolNode struct {
+	addr string
+}
+
+// config sets hook that puts the node into context of every query of the pool.
+func (n *poolNode) config(cfg dbsqlx.Config) dbsqlx.Config {
+	if cfg.Hook != nil {
+		cfg.Hook = poolNodeHook{Hook: cfg.Hook, node: n}
+	}
+
+	return cfg
+}
+
+type poolNodeHook struct {
+	dbhook.Hook
+	node *poolNode
+}
+
+func (h poolNodeHook) Before(ctx context.Context, input *dbhook.HookInput) (context.Context, error) {
+	return h.Hook.Before(context.WithValue(ctx, poolNodeContextKey{}, h.node), input) //nolint:wrapcheck // need clean err.
+}
+
+// drain waits until all connections of the pool are returned or
+// timeout is reached and closes the pool.
+func drain(db *sqlx.DB, timeout time.Duration) {
+	// Don't keep idle connections, returned connections will be closed.
+	db.SetMaxIdleConns(-1)
+
+	deadline := time.Now().Add(timeout)
+
+	ticker := time.NewTicker(_drainPollInterval)
+	defer ticker.Stop()
+
+	for db.Stats().InUse > 0 && time.Now().Before(deadline) {
+		<-ticker.C
+	}
+
+	_ = db.Close()
+}
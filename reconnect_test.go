@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loghole/database/dberrors"
+	"github.com/loghole/database/hooks"
+)
+
+func TestReconnector_reconnect(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    ReconnectPolicy
+		connErr   error
+		calls     int
+		wantCalls int64
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "single call",
+			policy:    ReconnectPolicy{MinInterval: time.Hour, MaxInterval: time.Hour},
+			calls:     1,
+			wantCalls: 1,
+			wantErr:   assert.NoError,
+		},
+		{
+			name:      "throttled after success",
+			policy:    ReconnectPolicy{MinInterval: time.Hour, MaxInterval: time.Hour},
+			calls:     3,
+			wantCalls: 1,
+			wantErr:   assert.NoError,
+		},
+		{
+			name:      "throttled after error",
+			policy:    ReconnectPolicy{MinInterval: time.Hour, MaxInterval: time.Hour},
+			connErr:   errors.New("connect error"),
+			calls:     3,
+			wantCalls: 1,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrReconnectThrottled, i...)
+			},
+		},
+		{
+			name:      "interval passed",
+			policy:    ReconnectPolicy{MinInterval: time.Nanosecond, MaxInterval: time.Nanosecond},
+			calls:     3,
+			wantCalls: 3,
+			wantErr:   assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+
+			r := newReconnector(tt.policy, func() error {
+				atomic.AddInt64(&calls, 1)
+
+				return tt.connErr
+			})
+
+			var err error
+
+			for i := 0; i < tt.calls; i++ {
+				time.Sleep(time.Millisecond)
+
+				err = r.reconnect(nil)
+			}
+
+			tt.wantErr(t, err, "reconnect()")
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt64(&calls), "connect calls")
+		})
+	}
+}
+
+func TestReconnector_concurrent(t *testing.T) {
+	var (
+		calls   int64
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+
+	r := newReconnector(ReconnectPolicy{MinInterval: time.Hour, MaxInterval: time.Hour}, func() error {
+		atomic.AddInt64(&calls, 1)
+		<-release
+
+		return nil
+	})
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, r.reconnect(nil))
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls), "connect calls")
+}
+
+func TestReconnector_prepare(t *testing.T) {
+	var calls, prepared int
+
+	r := newReconnector(ReconnectPolicy{MinInterval: time.Hour, MaxInterval: time.Hour}, func() error {
+		calls++
+
+		return nil
+	})
+
+	assert.NoError(t, r.reconnect(func() bool { prepared++; return false }))
+	assert.Equal(t, 0, calls, "attempt is not needed")
+
+	assert.NoError(t, r.reconnect(func() bool { prepared++; return true }))
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, r.reconnect(func() bool { prepared++; return true }))
+	assert.Equal(t, 1, calls, "throttled")
+	assert.Equal(t, 2, prepared, "must be called only for attempts")
+}
+
+func TestReconnector_backoff(t *testing.T) {
+	r := newReconnector(ReconnectPolicy{MinInterval: time.Second, MaxInterval: 5 * time.Second}, nil)
+
+	for failures, want := range []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	} {
+		r.failures = failures
+
+		assert.Equal(t, want, r.backoff(), "failures: %d", failures)
+	}
+}
+
+func TestDB_reconnectConcurrentQueries(t *testing.T) {
+	db := memorySQLLite(t, WithReconnectPolicy(ReconnectPolicy{
+		MinInterval:  time.Nanosecond,
+		MaxInterval:  time.Nanosecond,
+		DrainTimeout: time.Second,
+	}))
+	defer db.Close()
+
+	var (
+		ctx = context.Background()
+		wg  sync.WaitGroup
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				var val int
+
+				_ = db.GetContext(ctx, &val, "SELECT 1")
+				_ = db.reconnect()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var val int
+
+	require.NoError(t, db.GetContext(ctx, &val, "SELECT 1"))
+	assert.Equal(t, 1, val)
+}
+
+func TestDB_reconnectFailingOnConnect(t *testing.T) {
+	var failing atomic.Bool
+
+	db, err := New(&Config{Database: ":memory:", Type: SQLiteDatabase},
+		WithReconnectHook(func(err error) bool { return true }),
+		WithOnConnect(func(ctx context.Context, conn SessionConn) error {
+			if failing.Load() {
+				_, err := conn.ExecContext(ctx, "SELECT * FROM unknown")
+
+				return err
+			}
+
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	failing.Store(true)
+
+	done := make(chan error, 1)
+
+	go func() { done <- db.reconnect() }()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, dberrors.ErrConnectionInit)
+	case <-time.After(3 * time.Second):
+		t.Fatal("reconnect waits for itself")
+	}
+}
+
+func TestNew_failingOnConnect(t *testing.T) {
+	assert.NotPanics(t, func() {
+		_, err := New(&Config{Database: ":memory:", Type: SQLiteDatabase},
+			WithReconnectHook(func(err error) bool { return true }),
+			WithOnConnect(func(ctx context.Context, conn SessionConn) error {
+				_, err := conn.ExecContext(ctx, "SELECT * FROM unknown")
+
+				return err
+			}),
+		)
+
+		assert.ErrorIs(t, err, dberrors.ErrConnectionInit)
+	})
+}
+
+func TestDB_reconnectReplacedPoolError(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		policy = ReconnectPolicy{MinInterval: time.Nanosecond, MaxInterval: time.Nanosecond, DrainTimeout: time.Minute}
+	)
+
+	db, err := New(&Config{Database: ":memory:", Type: SQLiteDatabase},
+		WithReconnectHook(func(err error) bool { return true }),
+		WithReconnectPolicy(policy),
+		WithAddrPolicy(AddrPolicy{DownTimeout: time.Minute}),
+	)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	tx, err := db.SQLx().BeginTxx(ctx, nil)
+	require.NoError(t, err)
+
+	defer func() { _ = tx.Rollback() }()
+
+	// The pool is replaced, e.g. by rebalance, without excluding the node.
+	require.NoError(t, db.replacePool())
+
+	node := db.node.Load()
+
+	// The error of the replaced pool arrives after the switch.
+	_, err = tx.ExecContext(ctx, "SELECT * FROM unknown")
+	assert.ErrorIs(t, err, hooks.ErrCanRetry)
+	assert.Same(t, node, db.node.Load(), "must not reconnect")
+	assert.False(t, db.addrs.down(node.addr, time.Now()), "node of the new pool must not be excluded")
+
+	_, err = db.SQLx().ExecContext(ctx, "SELECT * FROM unknown")
+	assert.ErrorIs(t, err, hooks.ErrCanRetry)
+	assert.NotSame(t, node, db.node.Load(), "must reconnect")
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+const _redacted = "xxxxx"
+
+// secretParams are DSN params masked by RedactedDSN.
+//
+//nolint:gochecknoglobals // constant list.
+var secretParams = []string{"password", "sslpassword", "_auth_pass"}
+
+// RedactedDSN returns DSN with password and secret params masked.
+// All Addrs are joined with comma instead of picking one.
+func (cfg *Config) RedactedDSN() string {
+	var (
+		redacted = *cfg
+		creds    = cfg.credentials()
+		addr     = cfg.Addr
+	)
+
+	if creds.Password != "" {
+		creds.Password = _redacted
+	}
+
+	if len(cfg.Addrs) > 0 {
+		addr = strings.Join(cfg.Addrs, ",")
+	}
+
+	redacted.Params = cfg.params()
+
+	for _, key := range secretParams {
+		if _, ok := redacted.Params[key]; ok {
+			redacted.Params[key] = _redacted
+		}
+	}
+
+	return redacted.dsn(addr, creds)
+}
+
+// String returns database type and redacted DSN, so config can be logged safely.
+func (cfg Config) String() string { //nolint:gocritic // value receiver is required to print values.
+	return fmt.Sprintf("%s(%s)", cfg.Type, cfg.RedactedDSN())
+}
+
+// GoString masks secrets for %#v format.
+func (cfg Config) GoString() string { //nolint:gocritic // value receiver is required to print values.
+	return cfg.String()
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/loghole/database/dberrors"
+	"github.com/loghole/database/hooks"
+)
+
+// ReplicaStrategy defines how a replica is selected for read queries.
+type ReplicaStrategy int
+
+const (
+	// RoundRobinReplicas selects healthy replicas in turn.
+	RoundRobinReplicas ReplicaStrategy = iota
+	// LeastLatencyReplicas selects healthy replica with the lowest
+	// average query latency.
+	LeastLatencyReplicas
+)
+
+const (
+	DefaultReplicaDownTimeout = time.Second * 5
+
+	// Weight of the last observed latency in replica latency average.
+	_replicaLatencyWeight = 0.2
+)
+
+type primaryContextKey struct{}
+
+// WithPrimary returns context that forces read queries to the primary.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func isPrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryContextKey{}).(bool)
+
+	return forced
+}
+
+type replica struct {
+	db *DB
+
+	latency   atomic.Int64 // average query latency in nanoseconds.
+	downUntil atomic.Int64 // unix nano time until replica is excluded from routing.
+}
+
+// observe updates replica latency and excludes replica
+// from routing on connection errors.
+func (r *replica) observe(since time.Duration, err error, downTimeout time.Duration) {
+	if isConnectionError(err) {
+		r.downUntil.Store(time.Now().Add(downTimeout).UnixNano())
+
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	prev := r.latency.Load()
+	if prev == 0 {
+		r.latency.Store(int64(since))
+
+		return
+	}
+
+	r.latency.Store(int64(_replicaLatencyWeight*float64(since) + (1-_replicaLatencyWeight)*float64(prev)))
+}
+
+func (r *replica) healthy(now time.Time) bool {
+	if r.downUntil.Load() >= now.UnixNano() {
+		return false
+	}
+
+	return r.db == nil || r.db.health == nil || r.db.health.get().State != HealthDown
+}
+
+type replicaSet struct {
+	replicas    []*replica
+	strategy    ReplicaStrategy
+	downTimeout time.Duration
+	stickiness  time.Duration
+
+	next      atomic.Uint64
+	lastWrite atomic.Int64
+}
+
+// pick returns replica for read query or nil if query must go to the primary.
+func (s *replicaSet) pick() *replica {
+	now := time.Now()
+
+	if s.stickiness > 0 && now.Sub(time.Unix(0, s.lastWrite.Load())) < s.stickiness {
+		return nil
+	}
+
+	switch s.strategy {
+	case LeastLatencyReplicas:
+		return s.leastLatency(now)
+	default:
+		return s.roundRobin(now)
+	}
+}
+
+func (s *replicaSet) roundRobin(now time.Time) *replica {
+	start := s.next.Add(1)
+
+	for i := range s.replicas {
+		if r := s.replicas[(start+uint64(i))%uint64(len(s.replicas))]; r.healthy(now) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+func (s *replicaSet) leastLatency(now time.Time) *replica {
+	var best *replica
+
+	for _, r := range s.replicas {
+		if !r.healthy(now) {
+			continue
+		}
+
+		if best == nil || r.latency.Load() < best.latency.Load() {
+			best = r
+		}
+	}
+
+	return best
+}
+
+// markWrite records write to the primary for read-your-writes stickiness.
+func (s *replicaSet) markWrite() {
+	if s.stickiness > 0 {
+		s.lastWrite.Store(time.Now().UnixNano())
+	}
+}
+
+func (s *replicaSet) close() error {
+	errs := make([]error, 0, len(s.replicas))
+
+	for _, r := range s.replicas {
+		errs = append(errs, r.db.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// newReplicas connects to replicas with the same options as the primary.
+func newReplicas(ctx context.Context, cfg *Config, opts options, rawOpts []Option) (*replicaSet, error) {
+	set := &replicaSet{
+		replicas:    make([]*replica, 0, len(cfg.ReplicaAddrs)),
+		strategy:    opts.replicaStrategy,
+		downTimeout: opts.replicaDownTimeout,
+		stickiness:  opts.readYourWrites,
+	}
+
+	for _, addr := range cfg.ReplicaAddrs {
+		db, err := NewContext(ctx, cfg.replicaConfig(addr), rawOpts...)
+		if err != nil {
+			_ = set.close()
+
+			return nil, err
+		}
+
+		set.replicas = append(set.replicas, &replica{db: db})
+	}
+
+	return set, nil
+}
+
+// read runs read query on a healthy replica. It falls back to the primary
+// when there are no replicas, primary is forced by context or replica
+// connection is broken.
+func (db *DB) read(ctx context.Context, fn func(conn *sqlx.DB) error) error {
+	if db.replicas == nil || isPrimaryForced(ctx) {
+		return fn(db.SQLx())
+	}
+
+	r := db.replicas.pick()
+	if r == nil {
+		return fn(db.SQLx())
+	}
+
+	startedAt := time.Now()
+
+	routePending(ctx, r.db.pool)
+
+	err := fn(r.db.SQLx())
+
+	r.observe(time.Since(startedAt), err, db.replicas.downTimeout)
+
+	if isConnectionError(err) {
+		routePending(ctx, db.pool)
+
+		return fn(db.SQLx())
+	}
+
+	return err
+}
+
+// markWrite records write query for read-your-writes stickiness.
+func (db *DB) markWrite() {
+	if db.replicas != nil {
+		db.replicas.markWrite()
+	}
+}
+
+func isConnectionError(err error) bool {
+	return err != nil && (errors.Is(err, hooks.ErrCanRetry) || dberrors.IsConnectionFailure(err))
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.13.0"
+)
+
+func TestReplicaSet_pick(t *testing.T) {
+	newSet := func(strategy ReplicaStrategy, n int) *replicaSet {
+		set := &replicaSet{strategy: strategy, downTimeout: time.Minute}
+
+		for i := 0; i < n; i++ {
+			set.replicas = append(set.replicas, &replica{})
+		}
+
+		return set
+	}
+
+	tests := []struct {
+		name string
+		set  func() *replicaSet
+		want []int // indexes of picked replicas, -1 for primary.
+	}{
+		{
+			name: "round robin",
+			set:  func() *replicaSet { return newSet(RoundRobinReplicas, 3) },
+			want: []int{1, 2, 0, 1},
+		},
+		{
+			name: "round robin skip down",
+			set: func() *replicaSet {
+				set := newSet(RoundRobinReplicas, 3)
+				set.replicas[1].observe(0, driver.ErrBadConn, time.Minute)
+
+				return set
+			},
+			want: []int{2, 2, 0, 2},
+		},
+		{
+			name: "all down",
+			set: func() *replicaSet {
+				set := newSet(RoundRobinReplicas, 2)
+				set.replicas[0].observe(0, driver.ErrBadConn, time.Minute)
+				set.replicas[1].observe(0, driver.ErrBadConn, time.Minute)
+
+				return set
+			},
+			want: []int{-1, -1},
+		},
+		{
+			name: "least latency",
+			set: func() *replicaSet {
+				set := newSet(LeastLatencyReplicas, 3)
+				set.replicas[0].observe(time.Second, nil, time.Minute)
+				set.replicas[1].observe(time.Millisecond, nil, time.Minute)
+				set.replicas[2].observe(time.Minute, nil, time.Minute)
+
+				return set
+			},
+			want: []int{1, 1},
+		},
+		{
+			name: "read your writes",
+			set: func() *replicaSet {
+				set := newSet(RoundRobinReplicas, 2)
+				set.stickiness = time.Minute
+				set.markWrite()
+
+				return set
+			},
+			want: []int{-1, -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := tt.set()
+
+			for i, want := range tt.want {
+				got := set.pick()
+
+				if want == -1 {
+					assert.Nil(t, got, "pick #%d", i)
+
+					continue
+				}
+
+				assert.Same(t, set.replicas[want], got, "pick #%d", i)
+			}
+		})
+	}
+}
+
+func TestDB_readReplicas(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		tracer   = tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)).Tracer("")
+		ctx      = context.Background()
+	)
+
+	db, err := New(&Config{
+		Addr:         "primary",
+		ReplicaAddrs: []string{"replica"},
+		Database:     ":memory:",
+		Type:         SQLiteDatabase,
+	}, WithTracingHook(tracer))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var val int
+
+	require.NoError(t, db.GetContext(ctx, &val, "SELECT 1"))
+	require.NoError(t, db.SelectContext(ctx, &[]int{}, "SELECT 1"))
+	require.NoError(t, db.GetContext(WithPrimary(ctx), &val, "SELECT 1"))
+
+	_, err = db.ExecContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+
+	require.NoError(t, db.RunReadTxx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &val, "SELECT 1")
+	}))
+
+	var hosts []string
+
+	for _, span := range recorder.Ended() {
+		var host, statement string
+
+		for _, attr := range span.Attributes() {
+			switch attr.Key {
+			case semconv.HostNameKey:
+				host = attr.Value.AsString()
+			case semconv.DBStatementKey:
+				statement = attr.Value.AsString()
+			}
+		}
+
+		if statement == "SELECT 1" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	assert.Equal(t, []string{"replica", "replica", "primary", "primary", "replica"}, hosts)
+}
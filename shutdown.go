@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunningOperation describes operation that was running on shutdown.
+type RunningOperation struct {
+	Name      string
+	StartedAt time.Time
+}
+
+// ShutdownError is returned by Shutdown when operations were still running
+// after the context was done and were canceled.
+type ShutdownError struct {
+	Running []RunningOperation
+	Err     error
+}
+
+func (e *ShutdownError) Error() string {
+	names := make([]string, 0, len(e.Running))
+
+	for _, op := range e.Running {
+		names = append(names, op.Name)
+	}
+
+	return fmt.Sprintf("shutdown: %v: %d operations still running: %s", e.Err, len(e.Running), strings.Join(names, ", "))
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+type trackedOperation struct {
+	RunningOperation
+
+	cancel context.CancelFunc
+}
+
+// tracker tracks running operations and rejects new operations after shutdown.
+type tracker struct {
+	mu      sync.Mutex
+	closed  bool
+	nextID  uint64
+	running map[uint64]trackedOperation
+	drained chan struct{}
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		running: make(map[uint64]trackedOperation),
+		drained: make(chan struct{}),
+	}
+}
+
+// begin registers operation. If cancelable is true, returned context is
+// canceled on forced shutdown and when operation is done, otherwise the
+// context is returned as is.
+func (t *tracker) begin(ctx context.Context, name string, cancelable bool) (context.Context, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ctx, nil, ErrShutdown
+	}
+
+	op := trackedOperation{
+		RunningOperation: RunningOperation{Name: name, StartedAt: time.Now()},
+	}
+
+	if cancelable {
+		ctx, op.cancel = context.WithCancel(ctx)
+	}
+
+	t.nextID++
+
+	id := t.nextID
+	t.running[id] = op
+
+	return ctx, func() { t.done(id) }, nil
+}
+
+func (t *tracker) done(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if op, ok := t.running[id]; ok && op.cancel != nil {
+		op.cancel()
+	}
+
+	delete(t.running, id)
+
+	if t.closed && len(t.running) == 0 {
+		t.closeDrained()
+	}
+}
+
+// close rejects new operations.
+func (t *tracker) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+
+	if len(t.running) == 0 {
+		t.closeDrained()
+	}
+}
+
+func (t *tracker) closeDrained() {
+	select {
+	case <-t.drained:
+	default:
+		close(t.drained)
+	}
+}
+
+// shutdown rejects new operations and waits for running ones until ctx is
+// done. Operations that are still running are canceled and returned.
+func (t *tracker) shutdown(ctx context.Context) []RunningOperation {
+	t.close()
+
+	select {
+	case <-t.drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	running := make([]RunningOperation, 0, len(t.running))
+
+	for _, op := range t.running {
+		if op.cancel != nil {
+			op.cancel()
+		}
+
+		running = append(running, op.RunningOperation)
+	}
+
+	sort.Slice(running, func(i, j int) bool { return running[i].StartedAt.Before(running[j].StartedAt) })
+
+	return running
+}
+
+// Shutdown gracefully closes the database. It rejects new operations with
+// ErrShutdown and waits for running queries and transactions, including
+// their retries, until ctx is done. After that running operations are
+// canceled, database is closed and ShutdownError lists them.
+//
+// Rows returned by QueryxContext and NamedQueryContext and transactions
+// started with BeginTxx are tracked only until the method returns.
+func (db *DB) Shutdown(ctx context.Context) error {
+	running := db.tracker.shutdown(ctx)
+
+	closeErr := db.Close()
+
+	if len(running) > 0 {
+		return errors.Join(&ShutdownError{Running: running, Err: ctx.Err()}, closeErr)
+	}
+
+	return closeErr
+}
+
+// do runs operation with retries and tracks it for graceful shutdown.
+// The context passed to fn is canceled when fn returns, so fn must not
+// return objects bound to it.
+func (db *DB) do(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	ctx, done, err := db.tracker.begin(ctx, name, true)
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	return db.withRetry(ctx, name, func() error { return db.attempt(ctx, fn) })
+}
+
+// doKeepContext is like do but passes ctx as is. It is used for operations
+// that return rows or transactions bound to the context.
+func (db *DB) doKeepContext(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	ctx, done, err := db.tracker.begin(ctx, name, false)
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	return db.withRetry(ctx, name, func() error { return db.attempt(ctx, fn) })
+}
+
+// attempt runs fn as an attempt of operation which is pending
+// until it gets a connection.
+func (db *DB) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, done := db.pool.waitConn(ctx)
+	defer done()
+
+	return fn(ctx)
+}
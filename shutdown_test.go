@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Shutdown(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		txFn        func(release chan struct{}) TransactionFunc
+		wantErr     assert.ErrorAssertionFunc
+		wantRunning []string
+	}{
+		{
+			name:    "no running operations",
+			timeout: time.Second,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "wait running transaction",
+			timeout: time.Second,
+			txFn: func(release chan struct{}) TransactionFunc {
+				return func(ctx context.Context, tx *sqlx.Tx) error {
+					<-release
+
+					return nil
+				}
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "cancel running transaction",
+			timeout: time.Millisecond * 50,
+			txFn: func(release chan struct{}) TransactionFunc {
+				return func(ctx context.Context, tx *sqlx.Tx) error {
+					<-ctx.Done()
+
+					return ctx.Err()
+				}
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, context.DeadlineExceeded, i...)
+			},
+			wantRunning: []string{"RunTxxWithOptions"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				db      = memorySQLLite(t)
+				release = make(chan struct{})
+				started = make(chan struct{})
+				txErr   = make(chan error, 1)
+			)
+
+			if tt.txFn != nil {
+				go func() {
+					txErr <- db.RunTxx(context.Background(), func(ctx context.Context, tx *sqlx.Tx) error {
+						close(started)
+
+						return tt.txFn(release)(ctx, tx)
+					})
+				}()
+
+				<-started
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			shutdownErr := make(chan error, 1)
+
+			go func() { shutdownErr <- db.Shutdown(ctx) }()
+
+			require.Eventually(t, func() bool {
+				_, err := db.ExecContext(context.Background(), "SELECT 1")
+
+				return errors.Is(err, ErrShutdown)
+			}, time.Second, time.Millisecond)
+
+			close(release)
+
+			err := <-shutdownErr
+			tt.wantErr(t, err, "Shutdown()")
+
+			var shutdownError *ShutdownError
+
+			if errors.As(err, &shutdownError) {
+				names := make([]string, 0, len(shutdownError.Running))
+
+				for _, op := range shutdownError.Running {
+					names = append(names, op.Name)
+				}
+
+				assert.Equal(t, tt.wantRunning, names)
+			} else {
+				assert.Empty(t, tt.wantRunning)
+			}
+
+			if tt.txFn != nil {
+				<-txErr
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/loghole/database/hooks"
+)
+
+const DefaultPoolStatsInterval = time.Second * 15
+
+type poolStatsOptions struct {
+	collector hooks.PoolStatsCollector
+	pending   hooks.PendingRequestsCollector
+	deleter   hooks.PoolStatsDeleter
+	interval  time.Duration
+}
+
+// Stats returns statistics of connection pool. Connections of pools replaced
+// on reconnect are counted until they are closed, counters include replaced
+// pools, so they don't reset on reconnect.
+func (db *DB) Stats() sql.DBStats {
+	return db.pool.stats()
+}
+
+// WithPoolStats exports statistics of connection pool to collector every
+// interval. Statistics of read replicas are exported separately with
+// their addresses.
+//
+// If collector implements hooks.PendingRequestsCollector, the number of
+// operations waiting for a connection is exported too. If collector
+// implements hooks.PoolStatsDeleter, statistics of the previous address
+// are deleted after reconnect to other node and on Close.
+func WithPoolStats(collector hooks.PoolStatsCollector, interval time.Duration) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if collector == nil {
+			return fmt.Errorf("%w: pool stats collector must be non-empty", ErrInvalidConfig)
+		}
+
+		if interval <= 0 {
+			return fmt.Errorf("%w: pool stats interval must be greater than zero", ErrInvalidConfig)
+		}
+
+		pending, _ := collector.(hooks.PendingRequestsCollector)
+		deleter, _ := collector.(hooks.PoolStatsDeleter)
+
+		opts.poolStats = &poolStatsOptions{
+			collector: collector,
+			pending:   pending,
+			deleter:   deleter,
+			interval:  interval,
+		}
+
+		return nil
+	})
+}
+
+// WithPrometheusPoolStats exports statistics of connection pool
+// to prometheus every interval, see WithPoolStats.
+func WithPrometheusPoolStats(interval time.Duration, prometheusOpts ...PrometheusOption) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		collector, err := newPrometheusMetrics(prometheusOpts)
+		if err != nil {
+			return fmt.Errorf("init prometheus collector: %w", err)
+		}
+
+		return WithPoolStats(collector, interval).apply(opts, cfg)
+	})
+}
+
+// startPoolStats starts export of pool statistics.
+func (db *DB) startPoolStats() {
+	if db.options.poolStats == nil {
+		return
+	}
+
+	exporter := &poolStatsExporter{db: db, opts: db.options.poolStats, addr: db.hooksCfg.CurrentAddr()}
+	exporter.export()
+
+	exporter.periodic = startPeriodic(db.options.poolStats.interval, exporter.export)
+
+	db.poolStats = exporter
+}
+
+// poolStatsExporter exports statistics of the DB pool with the address
+// in use. Series of the DB are keyed by the last exported address, it is
+// deleted when the address changes and on close.
+type poolStatsExporter struct {
+	db       *DB
+	opts     *poolStatsOptions
+	periodic *periodic
+
+	mu   sync.Mutex
+	addr string
+	base sql.DBStats // counters at the moment the address was changed.
+}
+
+func (e *poolStatsExporter) export() {
+	var (
+		addr  = e.db.hooksCfg.CurrentAddr()
+		stats = e.db.Stats()
+	)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if addr != e.addr {
+		e.delete()
+		e.addr, e.base = addr, stats
+	}
+
+	e.opts.collector.PoolStatsObserve(e.db.hooksCfg.Type, addr, e.db.hooksCfg.Database, subCounters(stats, e.base))
+
+	if e.opts.pending != nil {
+		e.opts.pending.PendingRequestsObserve(
+			e.db.hooksCfg.Type,
+			addr,
+			e.db.hooksCfg.Database,
+			e.db.pool.pendingRequests(),
+		)
+	}
+}
+
+// close stops export and deletes statistics of the DB.
+func (e *poolStatsExporter) close() {
+	e.periodic.close()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.delete()
+}
+
+func (e *poolStatsExporter) delete() {
+	if e.opts.deleter != nil {
+		e.opts.deleter.PoolStatsDelete(e.db.hooksCfg.Type, e.addr, e.db.hooksCfg.Database)
+	}
+}
+
+// subCounters returns stats with counters of base subtracted.
+func subCounters(stats, base sql.DBStats) sql.DBStats { //nolint:gocritic // sql.DBStats is passed by value in database/sql.
+	stats.WaitCount -= base.WaitCount
+	stats.WaitDuration -= base.WaitDuration
+	stats.MaxIdleClosed -= base.MaxIdleClosed
+	stats.MaxIdleTimeClosed -= base.MaxIdleTimeClosed
+	stats.MaxLifetimeClosed -= base.MaxLifetimeClosed
+
+	return stats
+}
+
+// trackPending reports whether operations waiting for a connection are counted.
+func (o *options) trackPending() bool {
+	return o.poolStats != nil && o.poolStats.pending != nil
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loghole/database/hooks"
+	"github.com/loghole/database/internal/dbsqlx"
+)
+
+type poolStatsRecorder struct {
+	mu      sync.Mutex
+	addrs   []string
+	stats   []sql.DBStats
+	deleted []string
+}
+
+func (r *poolStatsRecorder) PoolStatsObserve(dbType, dbAddr, dbName string, stats sql.DBStats) { //nolint:gocritic // interface.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.addrs = append(r.addrs, dbType+"/"+dbAddr+"/"+dbName)
+	r.stats = append(r.stats, stats)
+}
+
+func (r *poolStatsRecorder) PoolStatsDelete(dbType, dbAddr, dbName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deleted = append(r.deleted, dbType+"/"+dbAddr+"/"+dbName)
+}
+
+func (r *poolStatsRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.stats)
+}
+
+func TestDB_Stats_reconnect(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := New(&Config{Database: ":memory:", Type: SQLiteDatabase, MaxIdleConns: -1})
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	require.NoError(t, db.PingContext(ctx))
+	require.NoError(t, db.PingContext(ctx))
+
+	closed := db.Stats().MaxIdleClosed
+	require.Positive(t, closed, "connections must be closed by MaxIdleConns")
+
+	old := db.SQLx()
+
+	sqlxDB, err := dbsqlx.NewSQLx(ctx, db.connectorConfig(""))
+	require.NoError(t, err)
+
+	db.pool.replace(sqlxDB)
+
+	assert.GreaterOrEqual(t, db.Stats().MaxIdleClosed, closed, "draining pool must be counted")
+
+	require.NoError(t, old.Close())
+	db.pool.retire(old)
+
+	assert.GreaterOrEqual(t, db.Stats().MaxIdleClosed, closed, "retired pool must be counted")
+
+	closed = db.Stats().MaxIdleClosed
+
+	require.NoError(t, db.PingContext(ctx))
+
+	assert.Greater(t, db.Stats().MaxIdleClosed, closed)
+}
+
+func TestWithPoolStats(t *testing.T) {
+	recorder := &poolStatsRecorder{}
+
+	tests := []struct {
+		name      string
+		collector hooks.PoolStatsCollector
+		interval  time.Duration
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "pass",
+			collector: recorder,
+			interval:  DefaultPoolStatsInterval,
+			wantErr:   assert.NoError,
+		},
+		{
+			name:     "empty collector",
+			interval: DefaultPoolStatsInterval,
+			wantErr:  assert.Error,
+		},
+		{
+			name:      "invalid interval",
+			collector: recorder,
+			wantErr:   assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts options
+
+			err := opts.apply(&hooks.Config{}, WithPoolStats(tt.collector, tt.interval))
+
+			tt.wantErr(t, err, "validate()")
+		})
+	}
+}
+
+func TestDB_startPoolStats(t *testing.T) {
+	recorder := &poolStatsRecorder{}
+
+	db, err := New(&Config{Addr: "local", Database: ":memory:", Type: SQLiteDatabase},
+		WithPoolStats(recorder, time.Millisecond))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return recorder.count() > 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, db.Close())
+
+	count := recorder.count()
+
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, count, recorder.count(), "export must be stopped by Close")
+	assert.Equal(t, "sqlite3/local/:memory:", recorder.addrs[0])
+}
+
+func TestDB_startPoolStats_addrChanged(t *testing.T) {
+	recorder := &poolStatsRecorder{}
+
+	db, err := New(&Config{Addr: "first", Database: ":memory:", Type: SQLiteDatabase, MaxIdleConns: -1},
+		WithPoolStats(recorder, time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, db.PingContext(context.Background()))
+
+	db.poolStats.export()
+
+	require.Positive(t, recorder.stats[len(recorder.stats)-1].MaxIdleClosed)
+
+	db.hooksCfg.Node.SetAddr("second")
+	db.poolStats.export()
+
+	assert.Equal(t, []string{"sqlite3/first/:memory:"}, recorder.deleted, "previous addr must be deleted")
+	assert.Equal(t, "sqlite3/second/:memory:", recorder.addrs[len(recorder.addrs)-1])
+	assert.Zero(t, recorder.stats[len(recorder.stats)-1].MaxIdleClosed, "counters of new addr must start from zero")
+
+	require.NoError(t, db.Close())
+
+	assert.Equal(t, []string{"sqlite3/first/:memory:", "sqlite3/second/:memory:"}, recorder.deleted,
+		"current addr must be deleted by Close")
+}
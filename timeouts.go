@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Timeouts defines connect and query timeouts. Zero value disables a timeout.
+//
+// Support by database type:
+//
+//	postgres, pgx: Connect, Statement, Lock (connect_timeout, statement_timeout, lock_timeout params)
+//	cockroach:     Connect (connect_timeout param), Statement, Lock (session settings)
+//	clickhouse:    all (dial_timeout, read_timeout, write_timeout, max_execution_time, lock_acquire_timeout params)
+//	sqlite3:       Lock (_busy_timeout param)
+//	mysql:         Connect, Read, Write (timeout, readTimeout, writeTimeout params), Lock (innodb_lock_wait_timeout)
+//
+// Timeouts in seconds are rounded up to a whole second.
+type Timeouts struct {
+	// Connect limits establishing of a new connection.
+	Connect time.Duration `yaml:"connect" json:"connect"`
+	// Statement limits execution of a statement on the server.
+	Statement time.Duration `yaml:"statement" json:"statement"`
+	// Read and Write limit network reads and writes.
+	Read  time.Duration `yaml:"read" json:"read"`
+	Write time.Duration `yaml:"write" json:"write"`
+	// Lock limits waiting for a lock.
+	Lock time.Duration `yaml:"lock" json:"lock"`
+}
+
+// supported reports timeouts supported by the database type.
+func (t *Timeouts) supported(dbType DBType) map[string]bool {
+	switch dbType {
+	case PostgresDatabase, PGXDatabase, CockroachDatabase:
+		return map[string]bool{"Connect": true, "Statement": true, "Lock": true}
+	case ClickhouseDatabase:
+		return map[string]bool{"Connect": true, "Statement": true, "Read": true, "Write": true, "Lock": true}
+	case SQLiteDatabase:
+		return map[string]bool{"Lock": true}
+	case MySQLDatabase:
+		return map[string]bool{"Connect": true, "Read": true, "Write": true, "Lock": true}
+	default:
+		return nil
+	}
+}
+
+func (t *Timeouts) validate(dbType DBType, invalid func(field, reason string)) {
+	supported := t.supported(dbType)
+
+	for _, timeout := range []struct {
+		field string
+		val   time.Duration
+	}{
+		{field: "Connect", val: t.Connect},
+		{field: "Statement", val: t.Statement},
+		{field: "Read", val: t.Read},
+		{field: "Write", val: t.Write},
+		{field: "Lock", val: t.Lock},
+	} {
+		switch {
+		case timeout.val < 0:
+			invalid("Timeouts."+timeout.field, "must not be negative")
+		case timeout.val > 0 && !supported[timeout.field]:
+			invalid("Timeouts."+timeout.field, "not supported")
+		}
+	}
+}
+
+// postgresParams sets timeouts params supported by lib/pq and pgx. Unknown
+// params are sent by both drivers to the server as runtime parameters.
+func (t *Timeouts) postgresParams(dbType DBType, params map[string]string) {
+	if t.Connect > 0 {
+		params["connect_timeout"] = seconds(t.Connect)
+	}
+
+	if dbType == CockroachDatabase {
+		return // cockroach timeouts are set by session settings.
+	}
+
+	if t.Statement > 0 {
+		params["statement_timeout"] = strconv.FormatInt(t.Statement.Milliseconds(), 10)
+	}
+
+	if t.Lock > 0 {
+		params["lock_timeout"] = strconv.FormatInt(t.Lock.Milliseconds(), 10)
+	}
+}
+
+func (t *Timeouts) clickhouseParams(params map[string]string) {
+	for key, val := range map[string]time.Duration{
+		"dial_timeout":  t.Connect,
+		"read_timeout":  t.Read,
+		"write_timeout": t.Write,
+	} {
+		if val > 0 {
+			params[key] = val.String()
+		}
+	}
+
+	if t.Statement > 0 {
+		params["max_execution_time"] = seconds(t.Statement)
+	}
+
+	if t.Lock > 0 {
+		params["lock_acquire_timeout"] = seconds(t.Lock)
+	}
+}
+
+func (t *Timeouts) sqliteParams(params map[string]string) {
+	if t.Lock > 0 {
+		params["_busy_timeout"] = strconv.FormatInt(t.Lock.Milliseconds(), 10)
+	}
+}
+
+// mysqlParams sets go-sql-driver/mysql timeouts params. Lock timeout is
+// set as innodb_lock_wait_timeout session variable.
+func (t *Timeouts) mysqlParams(params map[string]string) {
+	for key, val := range map[string]time.Duration{
+		"timeout":      t.Connect,
+		"readTimeout":  t.Read,
+		"writeTimeout": t.Write,
+	} {
+		if val > 0 {
+			params[key] = val.String()
+		}
+	}
+
+	if t.Lock > 0 {
+		params["innodb_lock_wait_timeout"] = seconds(t.Lock)
+	}
+}
+
+// reservedParams returns DSN params set by timeouts for the database type.
+func (t *Timeouts) reservedParams(dbType DBType) []string {
+	params := make(map[string]string)
+
+	switch dbType {
+	case PostgresDatabase, PGXDatabase, CockroachDatabase:
+		t.postgresParams(dbType, params)
+	case ClickhouseDatabase:
+		t.clickhouseParams(params)
+	case SQLiteDatabase:
+		t.sqliteParams(params)
+	case MySQLDatabase:
+		t.mysqlParams(params)
+	}
+
+	keys := make([]string, 0, len(params))
+
+	for key := range params {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// cockroachSession returns on connect callback that sets statement and lock
+// timeouts of cockroach session. It returns nil if they are not set.
+func (t *Timeouts) cockroachSession() OnConnectFunc {
+	var queries []string
+
+	if t.Statement > 0 {
+		queries = append(queries, fmt.Sprintf("SET statement_timeout = '%dms'", t.Statement.Milliseconds()))
+	}
+
+	if t.Lock > 0 {
+		queries = append(queries, fmt.Sprintf("SET lock_timeout = '%dms'", t.Lock.Milliseconds()))
+	}
+
+	if len(queries) == 0 {
+		return nil
+	}
+
+	return func(ctx context.Context, conn SessionConn) error {
+		for _, query := range queries {
+			if _, err := conn.ExecContext(ctx, query); err != nil {
+				return fmt.Errorf("set timeouts: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// seconds returns duration in whole seconds rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
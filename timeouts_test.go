@@ -0,0 +1,87 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_DSN_timeouts(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		want   string
+	}{
+		{
+			name: "postgres",
+			config: &Config{
+				Addr:     "127.0.0.1:5432",
+				User:     "postgres",
+				Database: "db",
+				Type:     PostgresDatabase,
+				Timeouts: Timeouts{Connect: 1500 * time.Millisecond, Statement: 30 * time.Second, Lock: time.Second},
+			},
+			want: "postgres://postgres@127.0.0.1:5432/db?connect_timeout=2&lock_timeout=1000&sslmode=disable&statement_timeout=30000",
+		},
+		{
+			name: "cockroach",
+			config: &Config{
+				Addr:     "127.0.0.1:26257",
+				User:     "root",
+				Database: "db",
+				Type:     CockroachDatabase,
+				Timeouts: Timeouts{Connect: time.Second, Statement: time.Second, Lock: time.Second},
+			},
+			want: "postgres://root@127.0.0.1:26257/db?connect_timeout=1&sslmode=disable",
+		},
+		{
+			name: "clickhouse",
+			config: &Config{
+				Addr:     "127.0.0.1:9000",
+				User:     "default",
+				Database: "db",
+				Type:     ClickhouseDatabase,
+				Timeouts: Timeouts{
+					Connect:   time.Second,
+					Statement: time.Minute,
+					Read:      10 * time.Second,
+					Write:     15 * time.Second,
+					Lock:      5 * time.Second,
+				},
+			},
+			want: "clickhouse://127.0.0.1:9000/db?dial_timeout=1s&lock_acquire_timeout=5&max_execution_time=60" +
+				"&read_timeout=10s&username=default&write_timeout=15s",
+		},
+		{
+			name: "mysql",
+			config: &Config{
+				Addr:     "127.0.0.1:3306",
+				User:     "root",
+				Database: "db",
+				Type:     MySQLDatabase,
+				Timeouts: Timeouts{Connect: time.Second, Read: 10 * time.Second, Write: 15 * time.Second, Lock: 1500 * time.Millisecond},
+			},
+			want: "root@tcp(127.0.0.1:3306)/db?innodb_lock_wait_timeout=2&parseTime=true&readTimeout=10s&timeout=1s&writeTimeout=15s",
+		},
+		{
+			name: "sqlite",
+			config: &Config{
+				Database: "file.db",
+				Type:     SQLiteDatabase,
+				Timeouts: Timeouts{Lock: 5 * time.Second},
+			},
+			want: "file.db?_busy_timeout=5000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.DSN())
+		})
+	}
+}
+
+func TestTimeouts_cockroachSession(t *testing.T) {
+	assert.Nil(t, (&Timeouts{Connect: time.Second}).cockroachSession())
+	assert.NotNil(t, (&Timeouts{Statement: time.Second}).cockroachSession())
+}
@@ -0,0 +1,418 @@
+package database
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/loghole/database/hooks"
+)
+
+// TLSMode is a mode of TLS connection. Values match postgres sslmode.
+type TLSMode string
+
+const (
+	// TLSDisable disables TLS.
+	TLSDisable TLSMode = "disable"
+	// TLSRequire encrypts connection without server certificate verification.
+	TLSRequire TLSMode = "require"
+	// TLSVerifyCA verifies that server certificate is signed by trusted CA.
+	TLSVerifyCA TLSMode = "verify-ca"
+	// TLSVerifyFull verifies server certificate and host name.
+	TLSVerifyFull TLSMode = "verify-full"
+)
+
+// TLSConfig defines TLS settings of connections.
+//
+// Files are supported by postgres, pgx, cockroach and mysql. ServerName and
+// in-memory Config are supported by pgx and mysql. Clickhouse supports Mode
+// with DSN params, other settings are passed by WithClickhouseConnector.
+type TLSConfig struct {
+	// Mode is a TLS mode, TLSVerifyFull by default.
+	Mode TLSMode `yaml:"mode" json:"mode"`
+
+	// CAFile is a path to root certificate.
+	CAFile string `yaml:"ca_file" json:"ca_file"`
+
+	// CertFile and KeyFile are paths to client certificate and key.
+	// They must be set together.
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+
+	// ServerName overrides host name used to verify server certificate.
+	ServerName string `yaml:"server_name" json:"server_name"`
+
+	// Config is an in-memory TLS config, it replaces files and mode settings.
+	Config *tls.Config `yaml:"-" json:"-"`
+}
+
+func (tc *TLSConfig) mode() TLSMode {
+	if tc.Mode == "" {
+		return TLSVerifyFull
+	}
+
+	return tc.Mode
+}
+
+func (tc *TLSConfig) validate(dbType DBType) []error {
+	var errs []error
+
+	invalid := func(field, reason string) {
+		errs = append(errs, &ConfigError{Field: "TLS." + field, Type: dbType, Reason: reason})
+	}
+
+	switch tc.mode() {
+	case TLSDisable, TLSRequire, TLSVerifyCA, TLSVerifyFull:
+	default:
+		invalid("Mode", fmt.Sprintf("unknown mode %q", tc.Mode))
+	}
+
+	if (tc.CertFile == "") != (tc.KeyFile == "") {
+		invalid("CertFile", "CertFile and KeyFile must be set together")
+	}
+
+	switch dbType {
+	case PGXDatabase, MySQLDatabase, ClickhouseDatabase:
+	case PostgresDatabase, CockroachDatabase:
+		if tc.ServerName != "" {
+			invalid("ServerName", "not supported, use pgx")
+		}
+
+		if tc.Config != nil {
+			invalid("Config", "not supported, use pgx")
+		}
+	default:
+		return append(errs, &ConfigError{Field: "TLS", Type: dbType, Reason: "not supported"})
+	}
+
+	for _, file := range []struct{ field, path string }{
+		{field: "CAFile", path: tc.CAFile},
+		{field: "CertFile", path: tc.CertFile},
+		{field: "KeyFile", path: tc.KeyFile},
+	} {
+		if file.path == "" {
+			continue
+		}
+
+		if _, err := os.Stat(file.path); err != nil {
+			invalid(file.field, err.Error())
+		}
+	}
+
+	return errs
+}
+
+// postgresParams sets sslmode and certificate params.
+func (tc *TLSConfig) postgresParams(params map[string]string) {
+	params["sslmode"] = string(tc.mode())
+
+	for key, val := range map[string]string{
+		"sslrootcert": tc.CAFile,
+		"sslcert":     tc.CertFile,
+		"sslkey":      tc.KeyFile,
+	} {
+		if val != "" {
+			params[key] = val
+		}
+	}
+}
+
+// clickhouseParams sets secure and skip_verify params.
+func (tc *TLSConfig) clickhouseParams(params map[string]string) {
+	switch tc.mode() {
+	case TLSDisable:
+	case TLSRequire:
+		params["secure"] = "true"
+		params["skip_verify"] = "true"
+	case TLSVerifyCA, TLSVerifyFull:
+		params["secure"] = "true"
+	}
+}
+
+// clickhouseCustom reports whether TLS config must be passed
+// by WithClickhouseConnector, DSN params support only mode.
+func (tc *TLSConfig) clickhouseCustom() bool {
+	if tc.Config != nil {
+		return true
+	}
+
+	if tc.mode() == TLSDisable {
+		return false
+	}
+
+	return tc.CAFile != "" || tc.CertFile != "" || tc.ServerName != ""
+}
+
+// ClickhouseConnectorFunc returns connector of clickhouse driver for dsn
+// with tlsConfig built from Config.TLS.
+type ClickhouseConnectorFunc func(dsn string, tlsConfig *tls.Config) (driver.Connector, error)
+
+// WithClickhouseConnector passes certificate files, ServerName and in-memory
+// Config of Config.TLS to clickhouse driver. The driver has no DSN params
+// for them, so fn sets tlsConfig to the driver options, e.g. for clickhouse-go:
+//
+//	func(dsn string, tlsConfig *tls.Config) (driver.Connector, error) {
+//		opts, err := clickhouse.ParseDSN(dsn)
+//		if err != nil {
+//			return nil, err
+//		}
+//
+//		opts.TLS = tlsConfig
+//
+//		return clickhouse.Connector(opts), nil
+//	}
+//
+// Files are read again for every new pool, so renewed certificates are used
+// after reconnect.
+func WithClickhouseConnector(fn ClickhouseConnectorFunc) Option {
+	return newFuncOption(func(opts *options, cfg *hooks.Config) error {
+		if cfg.Type != ClickhouseDatabase.String() {
+			return fmt.Errorf("%w: clickhouse connector requires %s database type", ErrInvalidConfig, ClickhouseDatabase)
+		}
+
+		if fn == nil {
+			return fmt.Errorf("%w: ClickhouseConnectorFunc must be non-empty", ErrInvalidConfig)
+		}
+
+		opts.clickhouseConnector = fn
+
+		return nil
+	})
+}
+
+// clickhouseConnector returns connector of fn with TLS config.
+func (tc *TLSConfig) clickhouseConnector(fn ClickhouseConnectorFunc) func(dsn string) (driver.Connector, error) {
+	return func(dsn string) (driver.Connector, error) {
+		tlsConfig, err := tc.build()
+		if err != nil {
+			return nil, err
+		}
+
+		connector, err := fn(dsn, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("open clickhouse connector: %w", err)
+		}
+
+		return connector, nil
+	}
+}
+
+// pgxConnector returns connector with in-memory TLS config.
+// It is used only when settings can't be passed with DSN.
+func (tc *TLSConfig) pgxConnector(dsn string) (driver.Connector, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse pgx config: %w", err)
+	}
+
+	tc.applyPGX(connConfig)
+
+	return stdlib.GetConnector(*connConfig), nil
+}
+
+// applyPGX sets in-memory TLS config and server name to pgx config.
+func (tc *TLSConfig) applyPGX(connConfig *pgx.ConnConfig) {
+	if tc.Config != nil {
+		connConfig.TLSConfig = tc.Config.Clone()
+		connConfig.Fallbacks = nil
+	}
+
+	if tc.ServerName != "" {
+		if connConfig.TLSConfig != nil {
+			connConfig.TLSConfig.ServerName = tc.ServerName
+		}
+
+		for _, fallback := range connConfig.Fallbacks {
+			if fallback.TLSConfig != nil {
+				fallback.TLSConfig.ServerName = tc.ServerName
+			}
+		}
+	}
+}
+
+func (tc *TLSConfig) inMemory() bool {
+	return tc.Config != nil || tc.ServerName != ""
+}
+
+// mysqlParams sets tls param. Settings which are not supported by the param
+// are passed with TLS config registered by mysqlConnector.
+func (tc *TLSConfig) mysqlParams(params map[string]string) {
+	switch mode := tc.mode(); {
+	case tc.mysqlCustom():
+		params["tls"] = tc.mysqlConfigName()
+	case mode == TLSDisable:
+		params["tls"] = "false"
+	case mode == TLSRequire:
+		params["tls"] = "skip-verify"
+	default:
+		params["tls"] = "true"
+	}
+}
+
+// mysqlCustom reports whether TLS config must be registered in mysql driver.
+func (tc *TLSConfig) mysqlCustom() bool {
+	if tc.Config != nil {
+		return true
+	}
+
+	switch tc.mode() {
+	case TLSDisable:
+		return false
+	case TLSVerifyCA:
+		return true
+	default:
+		return tc.CAFile != "" || tc.CertFile != "" || tc.ServerName != ""
+	}
+}
+
+// mysqlConfigName returns name of TLS config registered in mysql driver.
+// The name depends on settings only, so pools of one config share it.
+func (tc *TLSConfig) mysqlConfigName() string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s\x00%p",
+		tc.mode(), tc.CAFile, tc.CertFile, tc.KeyFile, tc.ServerName, tc.Config)))
+
+	return fmt.Sprintf("database-%x", hash[:8])
+}
+
+// mysqlConnector registers TLS config in mysql driver and returns connector.
+// Files are read again for every new pool, so renewed certificates are used
+// after reconnect.
+func (tc *TLSConfig) mysqlConnector(dsn string) (driver.Connector, error) {
+	tlsConfig, err := tc.build()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mysql.RegisterTLSConfig(tc.mysqlConfigName(), tlsConfig); err != nil {
+		return nil, fmt.Errorf("register mysql tls config: %w", err)
+	}
+
+	connector, err := mysql.MySQLDriver{}.OpenConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open mysql connector: %w", err)
+	}
+
+	return connector, nil
+}
+
+// build returns tls.Config from in-memory Config or from mode and files.
+func (tc *TLSConfig) build() (*tls.Config, error) {
+	if tc.Config != nil {
+		tlsConfig := tc.Config.Clone()
+
+		if tc.ServerName != "" {
+			tlsConfig.ServerName = tc.ServerName
+		}
+
+		return tlsConfig, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: tc.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if tc.CAFile != "" {
+		data, err := os.ReadFile(tc.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%w: %s", errNoCertificates, tc.CAFile)
+		}
+	}
+
+	if tc.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(tc.CertFile, tc.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch tc.mode() {
+	case TLSDisable, TLSVerifyFull:
+	case TLSRequire:
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // certificate is not verified in require mode.
+	case TLSVerifyCA:
+		// Verify chain without host name.
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // chain is verified by VerifyPeerCertificate.
+		tlsConfig.VerifyPeerCertificate = verifyChain(tlsConfig.RootCAs)
+	}
+
+	return tlsConfig, nil
+}
+
+var (
+	errNoCertificates     = errors.New("no certificates found")
+	errNoPeerCertificates = errors.New("server sent no certificates")
+)
+
+// verifyChain returns function that verifies server certificate chain
+// against roots, system roots are used if roots is nil.
+func verifyChain(roots *x509.CertPool) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errNoPeerCertificates
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("parse server certificate: %w", err)
+			}
+
+			certs = append(certs, cert)
+		}
+
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+
+		if _, err := certs[0].Verify(opts); err != nil {
+			return fmt.Errorf("verify server certificate: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// tlsConfig returns TLS settings. Deprecated CertPath is converted
+// to certificate files in CertPath directory.
+func (cfg *Config) tlsConfig() *TLSConfig {
+	if cfg.TLS != nil || cfg.CertPath == "" {
+		return cfg.TLS
+	}
+
+	switch cfg.Type {
+	case PostgresDatabase, PGXDatabase, CockroachDatabase:
+		return &TLSConfig{
+			Mode:     TLSVerifyFull,
+			CAFile:   filepath.Join(cfg.CertPath, "ca.crt"),
+			CertFile: filepath.Join(cfg.CertPath, "client.postgres.crt"),
+			KeyFile:  filepath.Join(cfg.CertPath, "client.postgres.key"),
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"crypto/tls"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSConfig_validate(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		caFile = filepath.Join(dir, "ca.crt")
+	)
+
+	require.NoError(t, os.WriteFile(caFile, []byte("ca"), 0o600))
+
+	tests := []struct {
+		name    string
+		tls     *TLSConfig
+		dbType  DBType
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "pass postgres",
+			tls:     &TLSConfig{Mode: TLSVerifyCA, CAFile: caFile},
+			dbType:  PostgresDatabase,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "pass pgx in-memory",
+			tls:     &TLSConfig{ServerName: "db.local", Config: &tls.Config{MinVersion: tls.VersionTLS12}},
+			dbType:  PGXDatabase,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "pass clickhouse",
+			tls:     &TLSConfig{Mode: TLSRequire},
+			dbType:  ClickhouseDatabase,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "pass mysql",
+			tls:     &TLSConfig{Mode: TLSVerifyCA, CAFile: caFile, ServerName: "db.local"},
+			dbType:  MySQLDatabase,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown mode",
+			tls:     &TLSConfig{Mode: "prefer-maybe"},
+			dbType:  PostgresDatabase,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "cert without key",
+			tls:     &TLSConfig{CertFile: caFile},
+			dbType:  PostgresDatabase,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "file not exists",
+			tls:     &TLSConfig{CAFile: filepath.Join(dir, "not-exists")},
+			dbType:  CockroachDatabase,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "in-memory config for lib/pq",
+			tls:     &TLSConfig{Config: &tls.Config{MinVersion: tls.VersionTLS12}},
+			dbType:  PostgresDatabase,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "pass clickhouse files",
+			tls:     &TLSConfig{CAFile: caFile, ServerName: "db.local"},
+			dbType:  ClickhouseDatabase,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "sqlite",
+			tls:     &TLSConfig{},
+			dbType:  SQLiteDatabase,
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := errors.Join(tt.tls.validate(tt.dbType)...)
+			if !tt.wantErr(t, err, "validate()") || err == nil {
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+}
+
+func TestTLSConfig_pgxConnector(t *testing.T) {
+	tlsCfg := &TLSConfig{ServerName: "db.local", Config: &tls.Config{MinVersion: tls.VersionTLS12}}
+
+	connector, err := tlsCfg.pgxConnector("postgres://user@127.0.0.1:5432/db?sslmode=verify-full")
+	require.NoError(t, err)
+	assert.NotNil(t, connector)
+
+	_, err = tlsCfg.pgxConnector("postgres://user@127.0.0.1:5432/db?sslmode=unknown")
+	assert.Error(t, err)
+}
+
+func TestTLSConfig_mysqlParams(t *testing.T) {
+	inMemory := &TLSConfig{Config: &tls.Config{MinVersion: tls.VersionTLS12}}
+
+	tests := []struct {
+		name string
+		tls  *TLSConfig
+		want string
+	}{
+		{name: "disable", tls: &TLSConfig{Mode: TLSDisable, CAFile: "/certs/ca.crt"}, want: "false"},
+		{name: "require", tls: &TLSConfig{Mode: TLSRequire}, want: "skip-verify"},
+		{name: "verify full", tls: &TLSConfig{}, want: "true"},
+		{name: "verify ca", tls: &TLSConfig{Mode: TLSVerifyCA}, want: (&TLSConfig{Mode: TLSVerifyCA}).mysqlConfigName()},
+		{name: "files", tls: &TLSConfig{CAFile: "/certs/ca.crt"}, want: (&TLSConfig{CAFile: "/certs/ca.crt"}).mysqlConfigName()},
+		{name: "in-memory", tls: inMemory, want: inMemory.mysqlConfigName()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := make(map[string]string)
+
+			tt.tls.mysqlParams(params)
+
+			assert.Equal(t, map[string]string{"tls": tt.want}, params)
+		})
+	}
+
+	assert.NotEqual(t, (&TLSConfig{CAFile: "a"}).mysqlConfigName(), (&TLSConfig{CAFile: "b"}).mysqlConfigName())
+}
+
+func TestTLSConfig_mysqlConnector(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+	tlsCfg := &TLSConfig{Config: &tls.Config{MinVersion: tls.VersionTLS12}}
+
+	connector, err := tlsCfg.mysqlConnector((&Config{
+		Addr: "127.0.0.1:3306",
+		User: "root",
+		Type: MySQLDatabase,
+		TLS:  tlsCfg,
+	}).DSN())
+	require.NoError(t, err)
+	assert.NotNil(t, connector)
+
+	tlsCfg = &TLSConfig{CAFile: caFile}
+
+	_, err = tlsCfg.mysqlConnector((&Config{Addr: "127.0.0.1:3306", Type: MySQLDatabase, TLS: tlsCfg}).DSN())
+	assert.ErrorIs(t, err, errNoCertificates)
+}
+
+func TestNew_tlsValidation(t *testing.T) {
+	_, err := New(&Config{
+		Addr:     "127.0.0.1:5432",
+		Database: "database",
+		Type:     PGXDatabase,
+		CertPath: filepath.Join(t.TempDir(), "certs"),
+	})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+type nopConnector struct {
+	driver.Connector
+}
+
+func TestTLSConfig_clickhouseConnector(t *testing.T) {
+	var (
+		tlsCfg = &TLSConfig{Mode: TLSVerifyFull, ServerName: "db.local"}
+		gotDSN string
+		gotTLS *tls.Config
+	)
+
+	open := tlsCfg.clickhouseConnector(func(dsn string, tlsConfig *tls.Config) (driver.Connector, error) {
+		gotDSN, gotTLS = dsn, tlsConfig
+
+		return nopConnector{}, nil
+	})
+
+	dsn := (&Config{Addr: "127.0.0.1:9000", Type: ClickhouseDatabase, TLS: tlsCfg}).DSN()
+
+	connector, err := open(dsn)
+	require.NoError(t, err)
+	assert.NotNil(t, connector)
+
+	assert.Equal(t, dsn, gotDSN)
+	require.NotNil(t, gotTLS)
+	assert.Equal(t, "db.local", gotTLS.ServerName)
+	assert.False(t, gotTLS.InsecureSkipVerify)
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+	tlsCfg = &TLSConfig{CAFile: caFile}
+
+	_, err = tlsCfg.clickhouseConnector(func(string, *tls.Config) (driver.Connector, error) {
+		return nopConnector{}, nil
+	})(dsn)
+	assert.ErrorIs(t, err, errNoCertificates)
+}
+
+func TestNew_clickhouseTLS(t *testing.T) {
+	cfg := &Config{
+		Addr: "127.0.0.1:9000",
+		Type: ClickhouseDatabase,
+		TLS:  &TLSConfig{ServerName: "db.local"},
+	}
+
+	_, err := New(cfg)
+	assert.ErrorIs(t, err, ErrInvalidConfig, "clickhouse connector is required")
+
+	_, err = New(&Config{Addr: "127.0.0.1:5432", Type: PGXDatabase}, WithClickhouseConnector(
+		func(string, *tls.Config) (driver.Connector, error) { return nopConnector{}, nil }))
+	assert.ErrorIs(t, err, ErrInvalidConfig, "clickhouse connector requires clickhouse type")
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/trace"
@@ -31,12 +32,14 @@ func (db *DB) RunTxx(ctx context.Context, fn TransactionFunc) error {
 
 // RunReadTxx runs transaction callback func with read only `sql.TxOptions`.
 // If an error occurs, the transaction will be retried if it allows `RetryFunc`.
+// The transaction is routed to a read replica if replicas are configured.
 func (db *DB) RunReadTxx(ctx context.Context, fn TransactionFunc) error {
 	return db.RunTxxWithOptions(ctx, &sql.TxOptions{ReadOnly: true}, fn)
 }
 
 // RunTxxWithOptions runs transaction callback func with custom `sql.TxOptions`.
 // If an error occurs, the transaction will be retried if it allows `RetryFunc`.
+// Read only transaction is routed to a read replica if replicas are configured.
 func (db *DB) RunTxxWithOptions(ctx context.Context, opts *sql.TxOptions, fn TransactionFunc) error {
 	ctx, span := trace.
 		SpanFromContext(ctx).
@@ -45,15 +48,32 @@ func (db *DB) RunTxxWithOptions(ctx context.Context, opts *sql.TxOptions, fn Tra
 		Start(ctx, _txSpanName, trace.WithSpanKind(trace.SpanKindInternal))
 	defer span.End()
 
-	return db.withRetry(ctx, func() error { return db.runTxx(ctx, opts, fn) })
+	if opts == nil || !opts.ReadOnly {
+		db.markWrite()
+
+		return db.do(ctx, "RunTxxWithOptions", func(ctx context.Context) error {
+			return db.runTxx(ctx, db.SQLx(), opts, fn)
+		})
+	}
+
+	return db.do(ctx, "RunTxxWithOptions", func(ctx context.Context) error {
+		return db.read(ctx, func(conn *sqlx.DB) error { return db.runTxx(ctx, conn, opts, fn) })
+	})
 }
 
-func (db *DB) runTxx(ctx context.Context, opts *sql.TxOptions, fn TransactionFunc) error {
-	tx, err := db.DB.BeginTxx(ctx, opts)
+func (db *DB) runTxx(ctx context.Context, conn *sqlx.DB, opts *sql.TxOptions, fn TransactionFunc) error {
+	tx, err := conn.BeginTxx(ctx, opts)
 	if err != nil {
 		return err
 	}
 
+	var (
+		startedAt = time.Now()
+		committed bool
+	)
+
+	// Observed after rollback.
+	defer func() { db.metrics.transaction(committed, time.Since(startedAt)) }()
 	defer db.rollback(tx)
 
 	if err := fn(ctx, tx); err != nil {
@@ -64,6 +84,8 @@ func (db *DB) runTxx(ctx context.Context, opts *sql.TxOptions, fn TransactionFun
 		return err //nolint:wrapcheck // need clean error
 	}
 
+	committed = true
+
 	return nil
 }
 
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConfigError describes invalid field of Config. It wraps ErrInvalidConfig.
+type ConfigError struct {
+	// Field is a name of the invalid field, e.g. "Addr" or "Params[sslmode]".
+	Field string
+	// Type is a database type the field is validated for.
+	Type   DBType
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("%s: %s: %s", ErrInvalidConfig, e.Field, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s: %s for %s", ErrInvalidConfig, e.Field, e.Reason, e.Type)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// Validate checks config for the database type. It returns joined
+// *ConfigError for every invalid field. New calls Validate before
+// connecting to the database.
+func (cfg *Config) Validate() error {
+	var (
+		errs  []error
+		known = true
+	)
+
+	invalid := func(field, reason string) {
+		errs = append(errs, &ConfigError{Field: field, Type: cfg.Type, Reason: reason})
+	}
+
+	switch cfg.Type {
+	case PostgresDatabase, PGXDatabase, CockroachDatabase:
+		// Unix socket directory is set by host param.
+		if cfg.Addr == "" && len(cfg.Addrs) == 0 && !hasParam(cfg.Params, "host") {
+			invalid("Addr", "Addr, Addrs or Params[host] required")
+		}
+
+		cfg.validateTimeoutsUnsupported(invalid)
+		cfg.validateReservedParams(invalid, cfg.TLS != nil, "sslmode", "sslrootcert", "sslcert", "sslkey")
+	case ClickhouseDatabase:
+		if cfg.Addr == "" {
+			invalid("Addr", "required")
+		}
+
+		if len(cfg.Addrs) > 0 {
+			invalid("Addrs", "not supported")
+		}
+
+		cfg.validateTimeouts(invalid)
+		cfg.validateReservedParams(invalid, true, "username")
+		cfg.validateReservedParams(invalid, strings.Contains(cfg.User, ":") || cfg.Credentials != nil, "password")
+		cfg.validateReservedParams(invalid, cfg.ReadTimeout != "", "read_timeout")
+		cfg.validateReservedParams(invalid, cfg.WriteTimeout != "", "write_timeout")
+		cfg.validateReservedParams(invalid, cfg.TLS != nil, "secure", "skip_verify")
+	case MySQLDatabase:
+		if cfg.Addr == "" {
+			invalid("Addr", "required")
+		}
+
+		if len(cfg.Addrs) > 0 {
+			invalid("Addrs", "not supported")
+		}
+
+		cfg.validateTimeoutsUnsupported(invalid)
+		cfg.validateReservedParams(invalid, cfg.TLS != nil, "tls")
+	case SQLiteDatabase:
+		if cfg.Database == "" {
+			invalid("Database", "required")
+		}
+
+		if len(cfg.Addrs) > 0 {
+			invalid("Addrs", "not supported")
+		}
+
+		cfg.validateTimeoutsUnsupported(invalid)
+	case "":
+		known = false
+
+		errs = append(errs, &ConfigError{Field: "Type", Reason: "required"})
+	default:
+		known = false
+
+		errs = append(errs, &ConfigError{Field: "Type", Reason: fmt.Sprintf("unknown database type %q", cfg.Type)})
+	}
+
+	for i, addr := range cfg.Addrs {
+		if addr == "" {
+			invalid(fmt.Sprintf("Addrs[%d]", i), "must be non-empty")
+		}
+	}
+
+	for i, addr := range cfg.ReplicaAddrs {
+		if addr == "" {
+			invalid(fmt.Sprintf("ReplicaAddrs[%d]", i), "must be non-empty")
+		}
+	}
+
+	keys := make([]string, 0, len(cfg.Params))
+
+	for key := range cfg.Params {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch {
+		case key == "":
+			invalid("Params", "empty key")
+		case strings.ContainsAny(key, "&=?#"):
+			invalid("Params["+key+"]", "key must not contain &, =, ? or #")
+		}
+	}
+
+	if known {
+		cfg.Timeouts.validate(cfg.Type, invalid)
+		cfg.validateReservedParams(invalid, true, cfg.Timeouts.reservedParams(cfg.Type)...)
+	}
+
+	if cfg.ReadTimeout != "" && cfg.Timeouts.Read > 0 {
+		invalid("Timeouts.Read", "conflicts with ReadTimeout")
+	}
+
+	if cfg.WriteTimeout != "" && cfg.Timeouts.Write > 0 {
+		invalid("Timeouts.Write", "conflicts with WriteTimeout")
+	}
+
+	if tlsCfg := cfg.tlsConfig(); tlsCfg != nil && known {
+		errs = append(errs, tlsCfg.validate(cfg.Type)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (cfg *Config) validateTimeouts(invalid func(field, reason string)) {
+	if _, err := time.ParseDuration(cfg.ReadTimeout); cfg.ReadTimeout != "" && err != nil {
+		invalid("ReadTimeout", "invalid duration "+cfg.ReadTimeout)
+	}
+
+	if _, err := time.ParseDuration(cfg.WriteTimeout); cfg.WriteTimeout != "" && err != nil {
+		invalid("WriteTimeout", "invalid duration "+cfg.WriteTimeout)
+	}
+}
+
+func (cfg *Config) validateTimeoutsUnsupported(invalid func(field, reason string)) {
+	if cfg.ReadTimeout != "" {
+		invalid("ReadTimeout", "not supported")
+	}
+
+	if cfg.WriteTimeout != "" {
+		invalid("WriteTimeout", "not supported")
+	}
+}
+
+// validateReservedParams reports params that are set from other fields.
+func (cfg *Config) validateReservedParams(invalid func(field, reason string), reserved bool, keys ...string) {
+	if !reserved {
+		return
+	}
+
+	for _, key := range keys {
+		if _, ok := cfg.Params[key]; ok {
+			invalid("Params["+key+"]", "conflicts with config field")
+		}
+	}
+}
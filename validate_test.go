@@ -0,0 +1,201 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     *Config
+		wantFields []string
+	}{
+		{
+			name: "pass postgres",
+			config: &Config{
+				Addr:     "127.0.0.1:5432",
+				User:     "postgres",
+				Database: "database",
+				Type:     PostgresDatabase,
+				Params:   map[string]string{"sslmode": "disable"},
+			},
+		},
+		{
+			name: "pass cockroach addrs",
+			config: &Config{
+				Addrs: []string{"127.0.0.1:26257", "127.0.0.2:26257"},
+				User:  "root",
+				Type:  CockroachDatabase,
+			},
+		},
+		{
+			name: "pass clickhouse",
+			config: &Config{
+				Addr:         "127.0.0.1:9000",
+				Database:     "database",
+				Type:         ClickhouseDatabase,
+				ReadTimeout:  "10s",
+				WriteTimeout: "15s",
+			},
+		},
+		{
+			name: "pass clickhouse password param",
+			config: &Config{
+				Addr:   "127.0.0.1:9000",
+				User:   "default",
+				Type:   ClickhouseDatabase,
+				Params: map[string]string{"password": "secret"},
+			},
+		},
+		{
+			name: "pass postgres unix socket",
+			config: &Config{
+				User:   "postgres",
+				Type:   PostgresDatabase,
+				Params: map[string]string{"host": "/var/run/postgresql"},
+			},
+		},
+		{
+			name: "pass mysql",
+			config: &Config{
+				Addr:     "127.0.0.1:3306",
+				User:     "root",
+				Database: "database",
+				Type:     MySQLDatabase,
+				Timeouts: Timeouts{Connect: time.Second, Lock: time.Second},
+				TLS:      &TLSConfig{ServerName: "db.local"},
+			},
+		},
+		{
+			name: "pass sqlite",
+			config: &Config{
+				Database: ":memory:",
+				Type:     SQLiteDatabase,
+			},
+		},
+		{
+			name:       "empty type",
+			config:     &Config{Database: ":memory:"},
+			wantFields: []string{"Type"},
+		},
+		{
+			name:       "unknown type",
+			config:     &Config{Database: ":memory:", Type: "qwerty", TLS: &TLSConfig{}},
+			wantFields: []string{"Type"},
+		},
+		{
+			name: "postgres without addr",
+			config: &Config{
+				Type:        PGXDatabase,
+				ReadTimeout: "1s",
+				Addrs:       []string{"127.0.0.1:5432", ""},
+			},
+			wantFields: []string{"ReadTimeout", "Addrs[1]"},
+		},
+		{
+			name:       "postgres without addr and host",
+			config:     &Config{Type: PostgresDatabase, Params: map[string]string{"sslmode": "disable"}},
+			wantFields: []string{"Addr"},
+		},
+		{
+			name: "postgres params conflict with tls",
+			config: &Config{
+				Addr:   "127.0.0.1:5432",
+				Type:   PGXDatabase,
+				Params: map[string]string{"sslmode": "disable"},
+				TLS:    &TLSConfig{Mode: "unknown"},
+			},
+			wantFields: []string{"Params[sslmode]", "TLS.Mode"},
+		},
+		{
+			name: "clickhouse invalid",
+			config: &Config{
+				Addrs:       []string{"127.0.0.1:9000"},
+				User:        "default:secret",
+				Type:        ClickhouseDatabase,
+				ReadTimeout: "10",
+				Params:      map[string]string{"password": "secret", "username": "default"},
+			},
+			wantFields: []string{"Addr", "Addrs", "ReadTimeout", "Params[username]", "Params[password]"},
+		},
+		{
+			name: "clickhouse password param with credentials",
+			config: &Config{
+				Addr:        "127.0.0.1:9000",
+				Type:        ClickhouseDatabase,
+				Credentials: EnvCredentials{},
+				Params:      map[string]string{"password": "secret"},
+			},
+			wantFields: []string{"Params[password]"},
+		},
+		{
+			name: "mysql invalid",
+			config: &Config{
+				Addrs:       []string{"127.0.0.1:3306"},
+				Type:        MySQLDatabase,
+				ReadTimeout: "10s",
+				Params:      map[string]string{"tls": "true", "timeout": "1s"},
+				Timeouts:    Timeouts{Connect: time.Second, Statement: time.Second},
+				TLS:         &TLSConfig{},
+			},
+			wantFields: []string{"Addr", "Addrs", "ReadTimeout", "Params[tls]", "Timeouts.Statement", "Params[timeout]"},
+		},
+		{
+			name: "timeouts invalid",
+			config: &Config{
+				Addr:        "127.0.0.1:9000",
+				Type:        ClickhouseDatabase,
+				ReadTimeout: "10s",
+				Timeouts:    Timeouts{Read: time.Second, Lock: -time.Second},
+			},
+			wantFields: []string{"Timeouts.Lock", "Timeouts.Read"},
+		},
+		{
+			name: "timeouts params conflict",
+			config: &Config{
+				Database: ":memory:",
+				Type:     SQLiteDatabase,
+				Params:   map[string]string{"_busy_timeout": "10"},
+				Timeouts: Timeouts{Lock: time.Second, Statement: time.Second},
+			},
+			wantFields: []string{"Timeouts.Statement", "Params[_busy_timeout]"},
+		},
+		{
+			name: "sqlite invalid",
+			config: &Config{
+				Type:         SQLiteDatabase,
+				ReplicaAddrs: []string{""},
+				Params:       map[string]string{"a&b": "1", "": "2", "c": "x#y"},
+				TLS:          &TLSConfig{},
+			},
+			wantFields: []string{"Database", "ReplicaAddrs[0]", "Params", "Params[a&b]", "TLS"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if len(tt.wantFields) == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+			assert.Equal(t, tt.wantFields, configErrorFields(t, err))
+		})
+	}
+}
+
+func TestConfigError_Error(t *testing.T) {
+	assert.Equal(t,
+		"invalid config: Addr: required for clickhouse",
+		(&ConfigError{Field: "Addr", Type: ClickhouseDatabase, Reason: "required"}).Error(),
+	)
+	assert.Equal(t,
+		"invalid config: Type: required",
+		(&ConfigError{Field: "Type", Reason: "required"}).Error(),
+	)
+}